@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var purgeOlderThan string
+
+// purge menghapus permanen menu yang sudah ada di trash lebih lama dari --older-than
+// contoh: core-api purge --older-than 30d
+var purgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "purge trashed menus",
+	Long:  "permanently delete menus that have been in the trash longer than --older-than",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThan, err := parseAge(purgeOlderThan)
+		if err != nil {
+			return err
+		}
+
+		cfg := config.NewConfig()
+
+		db, err := cfg.ConnectionPostgres()
+		if err != nil {
+			log.Error().Err(err).Msg("Error connecting to database")
+			return err
+		}
+
+		menuRepository := repository.NewMenuRepository(db.DB)
+		menuGroupRepository := repository.NewMenuGroupRepository(db.DB)
+		menuRevisionRepository := repository.NewMenuRevisionRepository(db.DB)
+		menuPublicationRepository := repository.NewMenuPublicationRepository(db.DB)
+		menuTranslationRepository := repository.NewMenuTranslationRepository(db.DB)
+		auditLogRepository := repository.NewAuditLogRepository(db.DB)
+		menuService := service.NewMenuService(menuRepository, menuGroupRepository, menuRevisionRepository, menuPublicationRepository, menuTranslationRepository, auditLogRepository, nil, cfg.Menu)
+
+		purged, err := menuService.PurgeMenu(context.Background(), olderThan)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "purged %d menus deleted more than %s ago\n", purged, purgeOlderThan)
+		return nil
+	},
+}
+
+// parseAge accepts any time.ParseDuration value plus a "d" suffix for days.
+func parseAge(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid --older-than value %q", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid --older-than value %q", value)
+	}
+
+	return age, nil
+}
+
+func init() {
+	purgeCmd.Flags().StringVar(&purgeOlderThan, "older-than", "30d", "only purge menus deleted longer ago than this (e.g. 30d, 72h)")
+	rootCmd.AddCommand(purgeCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/jwtauth"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	userUsername    string
+	userPassword    string
+	userRoles       []string
+	userPermissions []string
+	userScopes      []string
+)
+
+var userCmd = &cobra.Command{
+	Use:   "user",
+	Short: "manage user accounts",
+}
+
+// user create membuat akun baru, misalnya admin pertama
+// contoh: core-api user create --username admin --role admin
+// password dibaca dari stdin jika --password tidak diisi
+var userCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "create a user account",
+	Long:  "create a user account that can log in; the password is read from stdin when --password is not given",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password := userPassword
+		if password == "" {
+			line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if err != nil && line == "" {
+				return errors.New("no password given on --password or stdin")
+			}
+			password = strings.TrimRight(line, "\r\n")
+		}
+
+		if len(password) < 8 || len(password) > 72 {
+			return errors.New("password must be between 8 and 72 bytes long")
+		}
+
+		cfg := config.NewConfig()
+
+		db, err := cfg.ConnectionPostgres()
+		if err != nil {
+			log.Error().Err(err).Msg("Error connecting to database")
+			return err
+		}
+
+		scopes := userScopes
+		if !cmd.Flags().Changed("scope") {
+			scopes = []string{cfg.Auth.WriteScope}
+		}
+
+		userRepository := repository.NewUserRepository(db.DB)
+		revokedTokenRepository := repository.NewRevokedTokenRepository(db.DB)
+		// creating an account neither issues nor checks tokens, so no verifier is needed
+		authService := service.NewAuthService(userRepository, revokedTokenRepository, jwtauth.NewSigner(cfg.Auth), nil, cfg.Auth)
+
+		user := entity.UserEntity{
+			Username:    userUsername,
+			Roles:       userRoles,
+			Permissions: userPermissions,
+			Scopes:      scopes,
+		}
+
+		if err := authService.CreateUser(context.Background(), user, password); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cmd.OutOrStdout(), "created user %s with roles %v and scopes %v\n", user.Username, user.Roles, user.Scopes)
+		return nil
+	},
+}
+
+func init() {
+	userCreateCmd.Flags().StringVar(&userUsername, "username", "", "username to log in with")
+	userCreateCmd.Flags().StringVar(&userPassword, "password", "", "password, read from stdin when empty")
+	userCreateCmd.Flags().StringSliceVar(&userRoles, "role", []string{claims.RoleAdmin}, "roles of the user")
+	userCreateCmd.Flags().StringSliceVar(&userPermissions, "permission", nil, "permissions of the user")
+	userCreateCmd.Flags().StringSliceVar(&userScopes, "scope", nil, "scopes granted to the user's tokens (default AUTH_WRITE_SCOPE)")
+	userCreateCmd.MarkFlagRequired("username")
+
+	userCmd.AddCommand(userCreateCmd)
+	rootCmd.AddCommand(userCmd)
+}
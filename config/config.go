@@ -1,6 +1,14 @@
 package config
 
-import "github.com/spf13/viper"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
 
 type App struct {
 	AppPort string `json:"app_port"`
@@ -18,9 +26,72 @@ type PsqlDB struct {
 	MaxOpen  int    `json:"max_open"`
 }
 
+// MenuLimit caps the shape of a menu tree. Zero means unlimited.
+type MenuLimit struct {
+	MaxDepth    int `json:"max_depth"`
+	MaxChildren int `json:"max_children"`
+	MaxItems    int `json:"max_items"`
+}
+
+// Menu holds the global limits plus per root overrides keyed by root menu id.
+// An override value of zero falls back to the global one. DefaultLocale is the
+// locale menu names are written in, FallbackLocales are tried after the ones the
+// caller asks for when resolving translated names. CacheMaxEntries caps how many
+// serialized trees are kept in memory.
+type Menu struct {
+	Limits          MenuLimit
+	RootLimits      map[string]MenuLimit
+	DefaultLocale   string
+	FallbackLocales []string
+	CacheMaxEntries int
+}
+
+// Auth configures the JWT checks. Tokens are signed either with JWTSecret (HS256) or
+// with a key of the JWKS file (RS256, ES256). Issuer and Audience are only checked
+// when set, and write endpoints need a token granting WriteScope. Tokens issued by
+// the login endpoint are HS256 and an account is locked for LockoutDuration after
+// MaxFailedLogins wrong passwords in a row.
+type Auth struct {
+	JWTSecret       string
+	JWKSFile        string
+	Issuer          string
+	Audience        string
+	WriteScope      string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	MaxFailedLogins int
+	LockoutDuration time.Duration
+}
+
+// RateLimitRule allows Max requests per Window from one client. A Max of zero turns
+// the limit off.
+type RateLimitRule struct {
+	Max    int
+	Window time.Duration
+}
+
+// RateLimit holds the limits of each route group. Clients are told apart by one of
+// APIKeys sent in APIKeyHeader, else by their token subject, else by their IP, read
+// from X-Forwarded-For only behind TrustedProxies. IP is checked by IP alone before
+// tokens are verified. Store is memory, or postgres to share the counts between
+// replicas.
+type RateLimit struct {
+	IP             RateLimitRule
+	API            RateLimitRule
+	Write          RateLimitRule
+	Auth           RateLimitRule
+	APIKeyHeader   string
+	APIKeys        []string
+	TrustedProxies []string
+	Store          string
+}
+
 type Config struct {
-	App  App
-	Psql PsqlDB
+	App       App
+	Psql      PsqlDB
+	Menu      Menu
+	Auth      Auth
+	RateLimit RateLimit
 }
 
 func NewConfig() *Config {
@@ -40,5 +111,121 @@ func NewConfig() *Config {
 			MaxIdle:  viper.GetInt("DATABASE_MAX_IDLE_CONNECTION"),
 			MaxOpen:  viper.GetInt("DATABASE_MAX_OPEN_CONNECTION"),
 		},
+
+		Menu: Menu{
+			Limits: MenuLimit{
+				MaxDepth:    viper.GetInt("MENU_MAX_DEPTH"),
+				MaxChildren: viper.GetInt("MENU_MAX_CHILDREN"),
+				MaxItems:    viper.GetInt("MENU_MAX_ITEMS"),
+			},
+			RootLimits:      menuRootLimits(),
+			DefaultLocale:   viper.GetString("MENU_DEFAULT_LOCALE"),
+			FallbackLocales: commaList("MENU_FALLBACK_LOCALES"),
+			CacheMaxEntries: intOr("MENU_CACHE_MAX_ENTRIES", 1000),
+		},
+
+		Auth: Auth{
+			JWTSecret:  viper.GetString("AUTH_JWT_SECRET"),
+			JWKSFile:   viper.GetString("AUTH_JWKS_FILE"),
+			Issuer:     viper.GetString("AUTH_ISSUER"),
+			Audience:   viper.GetString("AUTH_AUDIENCE"),
+			WriteScope: stringOr("AUTH_WRITE_SCOPE", "menus:write"),
+
+			AccessTokenTTL:  durationOr("AUTH_ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL: durationOr("AUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			MaxFailedLogins: intOr("AUTH_MAX_FAILED_LOGINS", 5),
+			LockoutDuration: durationOr("AUTH_LOCKOUT_DURATION", 15*time.Minute),
+		},
+
+		RateLimit: RateLimit{
+			IP:             rateLimitRule("RATE_LIMIT_IP", RateLimitRule{Max: 300, Window: time.Minute}),
+			API:            rateLimitRule("RATE_LIMIT_API", RateLimitRule{Max: 100, Window: time.Minute}),
+			Write:          rateLimitRule("RATE_LIMIT_WRITE", RateLimitRule{Max: 30, Window: time.Minute}),
+			Auth:           rateLimitRule("RATE_LIMIT_AUTH", RateLimitRule{Max: 3, Window: time.Minute}),
+			APIKeyHeader:   stringOr("RATE_LIMIT_API_KEY_HEADER", "X-API-Key"),
+			APIKeys:        commaList("RATE_LIMIT_API_KEYS"),
+			TrustedProxies: commaList("RATE_LIMIT_TRUSTED_PROXIES"),
+			Store:          stringOr("RATE_LIMIT_STORE", "memory"),
+		},
 	}
 }
+
+// menuRootLimits reads MENU_ROOT_LIMITS, a JSON object such as
+// {"<root menu id>": {"max_depth": 2, "max_children": 8}}.
+func menuRootLimits() map[string]MenuLimit {
+	rootLimits := map[string]MenuLimit{}
+
+	raw := viper.GetString("MENU_ROOT_LIMITS")
+	if raw == "" {
+		return rootLimits
+	}
+
+	if err := json.Unmarshal([]byte(raw), &rootLimits); err != nil {
+		log.Error().Err(err).Msg("[CONFIG] invalid MENU_ROOT_LIMITS, per root limits are ignored")
+		return map[string]MenuLimit{}
+	}
+
+	return rootLimits
+}
+
+// commaList reads a comma separated list such as "en,id" from key.
+func commaList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(viper.GetString(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// stringOr reads key, fallback when unset.
+func stringOr(key string, fallback string) string {
+	if value := viper.GetString(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// durationOr reads a duration such as 15m from key, fallback when unset or not positive.
+func durationOr(key string, fallback time.Duration) time.Duration {
+	if value := viper.GetDuration(key); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// intOr reads a number from key, fallback when unset or not positive.
+func intOr(key string, fallback int) int {
+	if value := viper.GetInt(key); value > 0 {
+		return value
+	}
+	return fallback
+}
+
+// rateLimitRule reads a rule such as 100/1m, max requests per window, from key.
+// fallback is used when the key is unset or malformed.
+func rateLimitRule(key string, fallback RateLimitRule) RateLimitRule {
+	raw := viper.GetString(key)
+	if raw == "" {
+		return fallback
+	}
+
+	maxValue, windowValue, _ := strings.Cut(raw, "/")
+	maxRequests, err := strconv.Atoi(strings.TrimSpace(maxValue))
+	if err != nil || maxRequests < 0 {
+		log.Error().Str("value", raw).Msg("[CONFIG] invalid " + key + ", default limit is used")
+		return fallback
+	}
+
+	window := fallback.Window
+	if windowValue != "" {
+		window, err = time.ParseDuration(strings.TrimSpace(windowValue))
+		if err != nil || window <= 0 {
+			log.Error().Str("value", raw).Msg("[CONFIG] invalid " + key + ", default limit is used")
+			return fallback
+		}
+	}
+
+	return RateLimitRule{Max: maxRequests, Window: window}
+}
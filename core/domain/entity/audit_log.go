@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of audited targets.
+const (
+	AuditTargetMenu      = "menu"
+	AuditTargetMenuGroup = "menu_group"
+)
+
+// Audited operations besides the revision operations.
+const (
+	AuditPublish           = "publish"
+	AuditPurge             = "purge"
+	AuditSaveTranslation   = "save_translation"
+	AuditDeleteTranslation = "delete_translation"
+)
+
+// AuditLogEntity records who made a change, from where and to what. Changes lists
+// every field of the target that differs; it is empty when the change has no
+// field-level effect, such as publishing a group.
+type AuditLogEntity struct {
+	ID         int64               `json:"id"`
+	Actor      string              `json:"actor"`
+	ClientIP   string              `json:"client_ip"`
+	RequestID  string              `json:"request_id"`
+	Operation  string              `json:"operation"`
+	GroupID    uuid.UUID           `json:"group_id"`
+	TargetType string              `json:"target_type"`
+	TargetID   uuid.UUID           `json:"target_id"`
+	Changes    []AuditChangeEntity `json:"changes"`
+	CreatedAt  time.Time           `json:"created_at"`
+}
+
+// AuditChangeEntity is the value of one field before and after a change, null on the
+// side where the field or the target did not exist.
+type AuditChangeEntity struct {
+	Field  string          `json:"field"`
+	Before json.RawMessage `json:"before"`
+	After  json.RawMessage `json:"after"`
+}
+
+// AuditLogFilterEntity narrows the audit log listing; zero fields match everything.
+// From is inclusive and To exclusive.
+type AuditLogFilterEntity struct {
+	MenuID *uuid.UUID
+	Actor  string
+	From   *time.Time
+	To     *time.Time
+}
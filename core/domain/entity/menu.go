@@ -1,14 +1,152 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// Menu item types. Internal items point at an application route, external
+// items at an absolute URL, headers group their children under a label and
+// separators only draw a divider.
+const (
+	MenuTypeInternal  = "internal"
+	MenuTypeExternal  = "external"
+	MenuTypeHeader    = "header"
+	MenuTypeSeparator = "separator"
+)
+
+// Delete strategies for menus that still have children.
+const (
+	DeleteStrategyCascade  = "cascade"
+	DeleteStrategyReparent = "reparent"
+	DeleteStrategyReject   = "reject"
+)
+
+// MenuEntity is a menu item. VisibleFrom and VisibleUntil bound when it shows up in
+// the tree, nil being open ended. RequiredRoles and RequiredPermissions restrict who
+// sees it: any one of the roles and all of the permissions. HasChildren and
+// ChildCount count its live children, including those not loaded into Children; only
+// subtree reads fill them in. Locale is the locale Name was resolved from on
+// localized reads. Version grows with every write to the menu; on updates it is the
+// version the caller last saw.
 type MenuEntity struct {
-	ID        uuid.UUID    `json:"id"`
-	MenuID    *uuid.UUID   `json:"menu_id"`
-	Name      string       `json:"name"`
-	Depth     int          `json:"depth"`
-	SortOrder int          `json:"sort_order"`
-	Children  []MenuEntity `json:"children"`
+	ID                  uuid.UUID    `json:"id"`
+	GroupID             uuid.UUID    `json:"group_id"`
+	MenuID              *uuid.UUID   `json:"menu_id"`
+	Name                string       `json:"name"`
+	Locale              string       `json:"locale,omitempty"`
+	Type                string       `json:"type"`
+	URL                 string       `json:"url,omitempty"`
+	RouteName           string       `json:"route_name,omitempty"`
+	Icon                string       `json:"icon,omitempty"`
+	Target              string       `json:"target,omitempty"`
+	Rel                 string       `json:"rel,omitempty"`
+	Path                string       `json:"-"`
+	Depth               int          `json:"depth"`
+	SortOrder           int          `json:"sort_order"`
+	VisibleFrom         *time.Time   `json:"visible_from,omitempty"`
+	VisibleUntil        *time.Time   `json:"visible_until,omitempty"`
+	RequiredRoles       []string     `json:"required_roles,omitempty"`
+	RequiredPermissions []string     `json:"required_permissions,omitempty"`
+	Version             int64        `json:"version"`
+	UpdatedAt           time.Time    `json:"updated_at"`
+	DeletedAt           *time.Time   `json:"deleted_at,omitempty"`
+	HasChildren         bool         `json:"has_children,omitempty"`
+	ChildCount          int          `json:"child_count,omitempty"`
+	Children            []MenuEntity `json:"children"`
+}
+
+// Sort fields of the flat menu listing.
+const (
+	MenuSortOrder     = "sort_order"
+	MenuSortName      = "name"
+	MenuSortDepth     = "depth"
+	MenuSortUpdatedAt = "updated_at"
+)
+
+// MenuListEntity filters, sorts and pages the flat menu listing. Cursor is the opaque
+// value handed to clients; After is its decoded form, the sort value and id of the
+// last row of the previous page. Published lists the menus of the last publish
+// instead of the draft.
+type MenuListEntity struct {
+	GroupID      uuid.UUID
+	ParentID     *uuid.UUID
+	Depth        *int
+	Name         string
+	UpdatedSince *time.Time
+	Sort         string
+	Desc         bool
+	Limit        int
+	Cursor       string
+	After        *MenuCursorEntity
+	Published    bool
+}
+
+type MenuCursorEntity struct {
+	Value any
+	ID    uuid.UUID
+}
+
+// MenuQueryEntity selects part of a group's tree: the subtree of ID when set, or every
+// root of GroupID. MaxDepth limits it to that many levels below where it starts.
+// Published reads the tree of the last publish instead of the draft.
+type MenuQueryEntity struct {
+	GroupID   uuid.UUID
+	ID        *uuid.UUID
+	MaxDepth  *int
+	Published bool
+}
+
+// BreadcrumbEntity is one level of the chain from the root down to a menu. Siblings
+// lists every menu at that level, the level's own menu included, when requested.
+type BreadcrumbEntity struct {
+	MenuEntity
+	Siblings []MenuEntity `json:"siblings,omitempty"`
+}
+
+// MenuPathEntity is one step on the way from the root down to a menu.
+type MenuPathEntity struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// MenuSearchHitEntity is a menu matching a search, with how well it matched and the
+// ancestors leading to it, root first.
+type MenuSearchHitEntity struct {
+	MenuEntity
+	Score     float64          `json:"score"`
+	Ancestors []MenuPathEntity `json:"ancestors"`
+}
+
+// MoveMenuEntity describes where a menu should land. At most one of BeforeID,
+// AfterID and Position is set; none of them appends the menu to its new siblings.
+// Version is the version of the menu the caller last saw.
+type MoveMenuEntity struct {
+	ID       uuid.UUID
+	MenuID   *uuid.UUID
+	BeforeID *uuid.UUID
+	AfterID  *uuid.UUID
+	Position *int
+	Version  int64
+}
+
+// UpdateMenuEntity is the new state of a menu on update. The fields whose Set flag is
+// false were left out by the caller and keep the values the menu has.
+type UpdateMenuEntity struct {
+	MenuEntity
+	SortOrderSet           bool
+	VisibleFromSet         bool
+	VisibleUntilSet        bool
+	RequiredRolesSet       bool
+	RequiredPermissionsSet bool
+}
+
+// MenuTreeDiffEntity summarises what a whole-tree replace changed.
+type MenuTreeDiffEntity struct {
+	Created   []uuid.UUID `json:"created"`
+	Updated   []uuid.UUID `json:"updated"`
+	Moved     []uuid.UUID `json:"moved"`
+	Reordered []uuid.UUID `json:"reordered"`
+	Deleted   []uuid.UUID `json:"deleted"`
 }
@@ -0,0 +1,12 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+)
+
+type MenuGroupEntity struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Slug        string    `json:"slug"`
+	Description string    `json:"description"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Versions of a group's tree. The draft is the menus table itself, where every edit
+// lands; the published version is the copy of it taken by the last publish.
+const (
+	MenuVersionDraft     = "draft"
+	MenuVersionPublished = "published"
+)
+
+type MenuPublicationEntity struct {
+	GroupID     uuid.UUID `json:"group_id"`
+	PublishedBy string    `json:"published_by"`
+	PublishedAt time.Time `json:"published_at"`
+}
@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operations recorded in the revision history.
+const (
+	RevisionCreate          = "create"
+	RevisionUpdate          = "update"
+	RevisionDelete          = "delete"
+	RevisionMove            = "move"
+	RevisionReorder         = "reorder"
+	RevisionRestore         = "restore"
+	RevisionReplaceTree     = "replace_tree"
+	RevisionReorderChildren = "reorder_children"
+	RevisionRollback        = "rollback"
+	RevisionDiscardDraft    = "discard_draft"
+)
+
+// MenuRevisionEntity is one recorded change of a menu group. Before and After hold
+// the changed menu, nil when it did not exist on that side or when the change spans
+// the whole group. Changes is the state after the change of every menu it touched.
+// Snapshot is every live menu of the group after the change, only taken every so
+// often as the point the later changes are replayed from.
+type MenuRevisionEntity struct {
+	ID        int64                      `json:"id"`
+	GroupID   uuid.UUID                  `json:"group_id"`
+	MenuID    *uuid.UUID                 `json:"menu_id"`
+	Author    string                     `json:"author"`
+	Operation string                     `json:"operation"`
+	Before    *MenuEntity                `json:"before"`
+	After     *MenuEntity                `json:"after"`
+	Changes   []MenuRevisionChangeEntity `json:"changes,omitempty"`
+	Snapshot  []MenuEntity               `json:"snapshot,omitempty"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+// MenuRevisionChangeEntity is one menu touched by a change, Menu being nil when the
+// change took it out of the live tree.
+type MenuRevisionChangeEntity struct {
+	ID   uuid.UUID   `json:"id"`
+	Menu *MenuEntity `json:"menu"`
+}
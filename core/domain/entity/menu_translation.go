@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MenuTranslationEntity is the label of a menu in one locale.
+type MenuTranslationEntity struct {
+	MenuID    uuid.UUID `json:"menu_id"`
+	Locale    string    `json:"locale"`
+	Name      string    `json:"name"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserEntity is an account that can log in. Its roles, permissions and scopes are
+// copied into the tokens it is issued.
+type UserEntity struct {
+	ID           uuid.UUID  `json:"id"`
+	Username     string     `json:"username"`
+	PasswordHash string     `json:"-"`
+	Roles        []string   `json:"roles"`
+	Permissions  []string   `json:"permissions"`
+	Scopes       []string   `json:"scopes"`
+	FailedLogins int        `json:"-"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// TokenPairEntity is what a login or refresh hands out. ExpiresIn is the lifetime of
+// the access token in seconds.
+type TokenPairEntity struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
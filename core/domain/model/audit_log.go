@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditLog struct {
+	ID         int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	Actor      string    `gorm:"column:actor;not null"`
+	ClientIP   string    `gorm:"column:client_ip;not null"`
+	RequestID  string    `gorm:"column:request_id;not null"`
+	Operation  string    `gorm:"column:operation;not null"`
+	GroupID    uuid.UUID `gorm:"type:uuid;column:group_id;not null"`
+	TargetType string    `gorm:"column:target_type;not null"`
+	TargetID   uuid.UUID `gorm:"type:uuid;column:target_id;not null"`
+	Changes    []byte    `gorm:"column:changes;type:jsonb;not null"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
@@ -4,16 +4,31 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Menu struct {
-	ID        uuid.UUID  `gorm:"type:uuid;column:id;primaryKey;default:gen_random_uuid()"`
-	MenuID    *uuid.UUID `gorm:"type:uuid;index;column:menu_id"`
-	Name      string     `gorm:"column:name;not null"`
-	Depth     int        `gorm:"column:depth"`
-	SortOrder int        `gorm:"column:sort_order"`
-	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	ID                  uuid.UUID      `gorm:"type:uuid;column:id;primaryKey;default:gen_random_uuid()"`
+	GroupID             uuid.UUID      `gorm:"type:uuid;index;column:group_id;not null"`
+	MenuID              *uuid.UUID     `gorm:"type:uuid;index;column:menu_id"`
+	Name                string         `gorm:"column:name;not null"`
+	Type                string         `gorm:"column:type;not null;default:internal"`
+	URL                 string         `gorm:"column:url"`
+	RouteName           string         `gorm:"column:route_name"`
+	Icon                string         `gorm:"column:icon"`
+	Target              string         `gorm:"column:target"`
+	Rel                 string         `gorm:"column:rel"`
+	Path                string         `gorm:"column:path;type:ltree;not null"`
+	Depth               int            `gorm:"column:depth"`
+	SortOrder           int            `gorm:"column:sort_order"`
+	VisibleFrom         *time.Time     `gorm:"column:visible_from"`
+	VisibleUntil        *time.Time     `gorm:"column:visible_until"`
+	RequiredRoles       []string       `gorm:"column:required_roles;type:jsonb;serializer:json"`
+	RequiredPermissions []string       `gorm:"column:required_permissions;type:jsonb;serializer:json"`
+	Version             int64          `gorm:"column:version;not null;default:1"`
+	CreatedAt           time.Time      `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time      `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	DeletedAt           gorm.DeletedAt `gorm:"column:deleted_at;index"`
 }
 
 func (Menu) TableName() string {
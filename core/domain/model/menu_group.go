@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MenuGroup struct {
+	ID          uuid.UUID `gorm:"type:uuid;column:id;primaryKey;default:gen_random_uuid()"`
+	Name        string    `gorm:"column:name;not null"`
+	Slug        string    `gorm:"column:slug;uniqueIndex;not null"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (MenuGroup) TableName() string {
+	return "menu_groups"
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MenuPublication struct {
+	GroupID     uuid.UUID `gorm:"type:uuid;column:group_id;primaryKey"`
+	PublishedBy string    `gorm:"column:published_by;not null"`
+	PublishedAt time.Time `gorm:"column:published_at;not null"`
+}
+
+func (MenuPublication) TableName() string {
+	return "menu_publications"
+}
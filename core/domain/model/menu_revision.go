@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MenuRevision struct {
+	ID        int64      `gorm:"column:id;primaryKey;autoIncrement"`
+	GroupID   uuid.UUID  `gorm:"type:uuid;column:group_id;not null"`
+	MenuID    *uuid.UUID `gorm:"type:uuid;column:menu_id"`
+	Author    string     `gorm:"column:author;not null"`
+	Operation string     `gorm:"column:operation;not null"`
+	Before    []byte     `gorm:"column:before;type:jsonb"`
+	After     []byte     `gorm:"column:after;type:jsonb"`
+	Changes   []byte     `gorm:"column:changes;type:jsonb;not null"`
+	Snapshot  []byte     `gorm:"column:snapshot;type:jsonb"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (MenuRevision) TableName() string {
+	return "menu_revisions"
+}
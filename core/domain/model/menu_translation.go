@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MenuTranslation struct {
+	MenuID    uuid.UUID `gorm:"type:uuid;column:menu_id;primaryKey"`
+	Locale    string    `gorm:"column:locale;primaryKey"`
+	Name      string    `gorm:"column:name;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (MenuTranslation) TableName() string {
+	return "menu_translations"
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type RateLimit struct {
+	Key     string    `gorm:"column:key;primaryKey"`
+	Hits    int       `gorm:"column:hits;not null"`
+	ResetAt time.Time `gorm:"column:reset_at;not null"`
+}
+
+func (RateLimit) TableName() string {
+	return "rate_limits"
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type User struct {
+	ID           uuid.UUID  `gorm:"type:uuid;column:id;primaryKey;default:gen_random_uuid()"`
+	Username     string     `gorm:"column:username;unique;not null"`
+	PasswordHash string     `gorm:"column:password_hash;not null"`
+	Roles        []string   `gorm:"column:roles;type:jsonb;serializer:json"`
+	Permissions  []string   `gorm:"column:permissions;type:jsonb;serializer:json"`
+	Scopes       []string   `gorm:"column:scopes;type:jsonb;serializer:json"`
+	FailedLogins int        `gorm:"column:failed_logins;not null;default:0"`
+	LockedUntil  *time.Time `gorm:"column:locked_until"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time  `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+type RevokedToken struct {
+	JTI       string    `gorm:"column:jti;primaryKey"`
+	ExpiresAt time.Time `gorm:"column:expires_at;not null"`
+	RevokedAt time.Time `gorm:"column:revoked_at;autoCreateTime"`
+}
+
+func (RevokedToken) TableName() string {
+	return "revoked_tokens"
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/utils/actor"
+	"golang_menu_interview/utils/requestinfo"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// unauditedFields are menu fields that change as a side effect or only describe a
+// read, so they would only add noise to a diff.
+var unauditedFields = []string{"updated_at", "locale", "has_children", "child_count", "children"}
+
+// audit records a change to a target made with ctx. It must run in the transaction of
+// the change so the record and the change stand or fall together.
+func (m *MenuService) audit(ctx context.Context, operation string, groupID uuid.UUID, targetType string, targetID uuid.UUID, changes []entity.AuditChangeEntity) error {
+	info := requestinfo.FromContext(ctx)
+
+	auditLog := entity.AuditLogEntity{
+		Actor:      actor.FromContext(ctx),
+		ClientIP:   info.ClientIP,
+		RequestID:  info.RequestID,
+		Operation:  operation,
+		GroupID:    groupID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Changes:    changes,
+	}
+
+	if err := m.AuditLogRepoInterface.CreateAuditLog(ctx, auditLog); err != nil {
+		log.Err(err).Msg("[SERVICE] audit - 1")
+		return err
+	}
+
+	return nil
+}
+
+// auditMenus records one audit entry for every menu that differs between previous and
+// current, or a single entry against the group when none does, so the operation is
+// on record either way.
+func (m *MenuService) auditMenus(ctx context.Context, operation string, groupID uuid.UUID, previous, current []entity.MenuEntity) error {
+	previousByID := make(map[uuid.UUID]*entity.MenuEntity, len(previous))
+	for i := range previous {
+		previousByID[previous[i].ID] = &previous[i]
+	}
+
+	audited := false
+	record := func(id uuid.UUID, before, after *entity.MenuEntity) error {
+		changes, err := diffMenu(before, after)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] auditMenus - 1")
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		audited = true
+		return m.audit(ctx, operation, groupID, entity.AuditTargetMenu, id, changes)
+	}
+
+	for i := range current {
+		before := previousByID[current[i].ID]
+		delete(previousByID, current[i].ID)
+
+		if err := record(current[i].ID, before, &current[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range previous {
+		if _, gone := previousByID[previous[i].ID]; !gone {
+			continue
+		}
+		if err := record(previous[i].ID, &previous[i], nil); err != nil {
+			return err
+		}
+	}
+
+	if !audited {
+		return m.audit(ctx, operation, groupID, entity.AuditTargetMenuGroup, groupID, nil)
+	}
+	return nil
+}
+
+// diffMenu lists the fields that differ between before and after, in the order they
+// appear in the JSON of a menu. A nil side stands for a menu that does not exist.
+func diffMenu(before, after *entity.MenuEntity) ([]entity.AuditChangeEntity, error) {
+	beforeFields, order, err := menuFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, afterOrder, err := menuFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, field := range afterOrder {
+		if !slices.Contains(order, field) {
+			order = append(order, field)
+		}
+	}
+
+	changes := []entity.AuditChangeEntity{}
+	for _, field := range order {
+		if slices.Contains(unauditedFields, field) {
+			continue
+		}
+
+		oldValue, newValue := beforeFields[field], afterFields[field]
+		if bytes.Equal(oldValue, newValue) {
+			continue
+		}
+
+		changes = append(changes, entity.AuditChangeEntity{Field: field, Before: oldValue, After: newValue})
+	}
+
+	return changes, nil
+}
+
+// menuFields returns the JSON value of each field of menu, and the field names in
+// order. Null fields have a nil value; every field of a nil menu is absent.
+func menuFields(menu *entity.MenuEntity) (map[string]json.RawMessage, []string, error) {
+	if menu == nil {
+		return nil, nil, nil
+	}
+
+	data, err := json.Marshal(menu)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	var order []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		field, _ := token.(string)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if string(value) == "null" {
+			value = nil
+		}
+
+		fields[field] = value
+		order = append(order, field)
+	}
+
+	return fields, order, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/internal/adapter/repository"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultAuditLogListLimit is the page size of the audit log listing.
+const defaultAuditLogListLimit = 50
+
+// auditLogExportBatch is how many records an export reads at a time.
+const auditLogExportBatch = 500
+
+type AuditLogServiceInterface interface {
+	FindAllAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, cursor string, limit int) ([]entity.AuditLogEntity, string, error)
+	ExportAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, write func(entity.AuditLogEntity) error) error
+}
+
+type AuditLogService struct {
+	AuditLogRepoInterface repository.AuditLogRepositoryInterface
+}
+
+func NewAuditLogService(auditLogRepoInterface repository.AuditLogRepositoryInterface) AuditLogServiceInterface {
+	return &AuditLogService{
+		AuditLogRepoInterface: auditLogRepoInterface,
+	}
+}
+
+// FindAllAuditLog implements AuditLogServiceInterface.
+// It returns one page of the matching records, newest first, and the cursor of the
+// next page.
+func (a *AuditLogService) FindAllAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, cursor string, limit int) ([]entity.AuditLogEntity, string, error) {
+	var beforeID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", errors.New("invalid cursor")
+		}
+		beforeID = id
+	}
+
+	if limit <= 0 {
+		limit = defaultAuditLogListLimit
+	}
+
+	auditLogs, err := a.AuditLogRepoInterface.FindAllAuditLog(ctx, filter, beforeID, limit+1)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindAllAuditLog - 1")
+		return nil, "", err
+	}
+
+	if len(auditLogs) <= limit {
+		return auditLogs, "", nil
+	}
+
+	auditLogs = auditLogs[:limit]
+	return auditLogs, strconv.FormatInt(auditLogs[limit-1].ID, 10), nil
+}
+
+// ExportAuditLog implements AuditLogServiceInterface.
+// Every matching record is passed to write, newest first, reading them in batches so
+// a large export never sits in memory at once.
+func (a *AuditLogService) ExportAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, write func(entity.AuditLogEntity) error) error {
+	var beforeID int64
+	for {
+		auditLogs, err := a.AuditLogRepoInterface.FindAllAuditLog(ctx, filter, beforeID, auditLogExportBatch)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] ExportAuditLog - 1")
+			return err
+		}
+
+		for _, auditLog := range auditLogs {
+			if err := write(auditLog); err != nil {
+				return err
+			}
+		}
+
+		if len(auditLogs) < auditLogExportBatch {
+			return nil
+		}
+		beforeID = auditLogs[len(auditLogs)-1].ID
+	}
+}
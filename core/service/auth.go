@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/jwtauth"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthServiceInterface interface {
+	CreateUser(ctx context.Context, req entity.UserEntity, password string) error
+	Login(ctx context.Context, username, password string) (*entity.TokenPairEntity, error)
+	Refresh(ctx context.Context, refreshToken string) (*entity.TokenPairEntity, error)
+	Logout(ctx context.Context, refreshToken string) error
+}
+
+type AuthService struct {
+	UserRepoInterface         repository.UserRepositoryInterface
+	RevokedTokenRepoInterface repository.RevokedTokenRepositoryInterface
+	Signer                    *jwtauth.Signer
+	Verifier                  *jwtauth.Verifier
+	Config                    config.Auth
+}
+
+func NewAuthService(userRepoInterface repository.UserRepositoryInterface, revokedTokenRepoInterface repository.RevokedTokenRepositoryInterface, signer *jwtauth.Signer, verifier *jwtauth.Verifier, cfg config.Auth) AuthServiceInterface {
+	return &AuthService{
+		UserRepoInterface:         userRepoInterface,
+		RevokedTokenRepoInterface: revokedTokenRepoInterface,
+		Signer:                    signer,
+		Verifier:                  verifier,
+		Config:                    cfg,
+	}
+}
+
+// dummyHash is compared against when the username is unknown, so a login takes as
+// long whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// CreateUser implements AuthServiceInterface.
+func (a *AuthService) CreateUser(ctx context.Context, req entity.UserEntity, password string) error {
+	if _, err := a.UserRepoInterface.FindUserByUsername(ctx, req.Username); err == nil {
+		return errors.New("username already exists")
+	} else if err.Error() != "user not found" {
+		log.Err(err).Msg("[SERVICE] CreateUser - 1")
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] CreateUser - 2")
+		return err
+	}
+	req.PasswordHash = string(hash)
+
+	return a.UserRepoInterface.CreateUser(ctx, req)
+}
+
+// Login implements AuthServiceInterface.
+// A locked account is refused before its password is checked. Every wrong password
+// counts towards the lockout, a right one clears the count.
+func (a *AuthService) Login(ctx context.Context, username, password string) (*entity.TokenPairEntity, error) {
+	user, err := a.UserRepoInterface.FindUserByUsername(ctx, username)
+	if err != nil {
+		if err.Error() == "user not found" {
+			bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+			return nil, errors.New("invalid username or password")
+		}
+		log.Err(err).Msg("[SERVICE] Login - 1")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if user.LockedUntil != nil && now.Before(*user.LockedUntil) {
+		return nil, errors.New("account is locked")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		lockedUntil, err := a.UserRepoInterface.RecordFailedLogin(ctx, user.ID, a.Config.MaxFailedLogins, now.Add(a.Config.LockoutDuration))
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] Login - 2")
+			return nil, err
+		}
+		if lockedUntil != nil {
+			log.Warn().Str("username", user.Username).Time("locked_until", *lockedUntil).Msg("[SERVICE] Login - account locked")
+			return nil, errors.New("account is locked")
+		}
+		return nil, errors.New("invalid username or password")
+	}
+
+	if user.FailedLogins > 0 || user.LockedUntil != nil {
+		if err := a.UserRepoInterface.ResetFailedLogins(ctx, user.ID); err != nil {
+			log.Err(err).Msg("[SERVICE] Login - 3")
+			return nil, err
+		}
+	}
+
+	return a.issueTokens(user)
+}
+
+// Refresh implements AuthServiceInterface.
+// The refresh token is single use: it is revoked and replaced by a new one, with an
+// access token carrying the current roles and scopes of the user.
+func (a *AuthService) Refresh(ctx context.Context, refreshToken string) (*entity.TokenPairEntity, error) {
+	refresh, err := a.Verifier.VerifyRefresh(ctx, refreshToken)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] Refresh - 1")
+		return nil, errors.New("invalid refresh token")
+	}
+
+	user, err := a.UserRepoInterface.FindUserByUsername(ctx, refresh.Subject)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] Refresh - 2")
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if user.LockedUntil != nil && time.Now().UTC().Before(*user.LockedUntil) {
+		return nil, errors.New("account is locked")
+	}
+
+	if err := a.RevokedTokenRepoInterface.RevokeToken(ctx, refresh.TokenID, refresh.ExpiresAt); err != nil {
+		log.Err(err).Msg("[SERVICE] Refresh - 3")
+		if err.Error() == "token already revoked" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	return a.issueTokens(user)
+}
+
+// Logout implements AuthServiceInterface.
+// Both the refresh token and the access token the request was made with are revoked.
+func (a *AuthService) Logout(ctx context.Context, refreshToken string) error {
+	refresh, err := a.Verifier.VerifyRefresh(ctx, refreshToken)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] Logout - 1")
+		return errors.New("invalid refresh token")
+	}
+
+	caller := claims.FromContext(ctx)
+	if caller.Subject != "" && caller.Subject != refresh.Subject {
+		return errors.New("refresh token belongs to another user")
+	}
+
+	for _, token := range []claims.Claims{refresh, caller} {
+		if token.TokenID == "" {
+			continue
+		}
+		if err := a.RevokedTokenRepoInterface.RevokeToken(ctx, token.TokenID, token.ExpiresAt); err != nil && err.Error() != "token already revoked" {
+			log.Err(err).Msg("[SERVICE] Logout - 2")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *AuthService) issueTokens(user *entity.UserEntity) (*entity.TokenPairEntity, error) {
+	caller := claims.Claims{
+		Subject:     user.Username,
+		Roles:       user.Roles,
+		Permissions: user.Permissions,
+		Scopes:      user.Scopes,
+	}
+
+	accessToken, _, err := a.Signer.Sign(caller, jwtauth.TokenAccess, a.Config.AccessTokenTTL)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] issueTokens - 1")
+		return nil, err
+	}
+
+	refreshToken, _, err := a.Signer.Sign(claims.Claims{Subject: user.Username}, jwtauth.TokenRefresh, a.Config.RefreshTokenTTL)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] issueTokens - 2")
+		return nil, err
+	}
+
+	return &entity.TokenPairEntity{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(a.Config.AccessTokenTTL / time.Second),
+	}, nil
+}
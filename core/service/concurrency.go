@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_menu_interview/core/domain/entity"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// VersionConflictError is returned when a write names a version of the menu that is
+// no longer current. Current is the menu as it stands now.
+type VersionConflictError struct {
+	Current *entity.MenuEntity
+}
+
+func (e *VersionConflictError) Error() string {
+	return fmt.Sprintf("menu version conflict: current version is %d", e.Current.Version)
+}
+
+// checkVersion refuses a write to current made against any other version.
+func checkVersion(current *entity.MenuEntity, version int64) error {
+	if version <= 0 {
+		return errors.New("menu version is required")
+	}
+	if current.Version != version {
+		return &VersionConflictError{Current: current}
+	}
+	return nil
+}
+
+// lockVersion locks the menu for the rest of the transaction and checks it is still at
+// version. It must run inside the transaction of the write.
+func (m *MenuService) lockVersion(ctx context.Context, id uuid.UUID, version int64) error {
+	locked, err := m.MenuRepoInterface.LockMenu(ctx, id)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] lockVersion - 1")
+		return err
+	}
+
+	return checkVersion(locked, version)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// menuCursor is the JSON behind the opaque cursor of the flat menu listing. Sort ties
+// the cursor to the order it was issued for.
+type menuCursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    uuid.UUID       `json:"id"`
+}
+
+// encodeMenuCursor returns the cursor pointing just after menu in the given sort.
+func encodeMenuCursor(sort string, menu entity.MenuEntity) (string, error) {
+	var value any
+	switch sort {
+	case entity.MenuSortName:
+		value = menu.Name
+	case entity.MenuSortDepth:
+		value = menu.Depth
+	case entity.MenuSortUpdatedAt:
+		value = menu.UpdatedAt
+	default:
+		value = menu.SortOrder
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(menuCursor{Sort: sort, Value: raw, ID: menu.ID})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeMenuCursor parses a cursor issued for sort back into its typed sort value and id.
+func decodeMenuCursor(sort, cursor string) (*entity.MenuCursorEntity, error) {
+	invalid := errors.New("invalid cursor")
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var c menuCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return nil, invalid
+	}
+
+	var value any
+	switch sort {
+	case entity.MenuSortName:
+		var name string
+		err = json.Unmarshal(c.Value, &name)
+		value = name
+	case entity.MenuSortUpdatedAt:
+		var updatedAt time.Time
+		err = json.Unmarshal(c.Value, &updatedAt)
+		value = updatedAt
+	default:
+		var number int
+		err = json.Unmarshal(c.Value, &number)
+		value = number
+	}
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &entity.MenuCursorEntity{Value: value, ID: c.ID}, nil
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/internal/adapter/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	LimitMaxDepth    = "max_depth"
+	LimitMaxChildren = "max_children"
+	LimitMaxItems    = "max_items"
+)
+
+// LimitError is returned when a change would break one of the configured menu limits.
+type LimitError struct {
+	Limit string `json:"limit"`
+	Max   int    `json:"max"`
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("menu limit exceeded: %s is %d", e.Limit, e.Max)
+}
+
+// subtreeShape describes the subtree being placed: a single new menu on create, or
+// the moved menu with its descendants on move.
+type subtreeShape struct {
+	ID       uuid.UUID
+	Existing bool
+	ParentID *uuid.UUID
+	RootID   uuid.UUID
+	Height   int
+	Size     int
+}
+
+// rootOf returns the id of the root menu at the top of path.
+func rootOf(path string) uuid.UUID {
+	ids := repository.PathIDs(path)
+	if len(ids) == 0 {
+		return uuid.Nil
+	}
+	return ids[0]
+}
+
+// limitsFor returns the limits of the tree under rootID. A per root depth or children
+// value replaces the global one when set; MaxItems only ever holds the per root value.
+func (m *MenuService) limitsFor(rootID uuid.UUID) config.MenuLimit {
+	limits := m.Config.Limits
+	limits.MaxItems = 0
+
+	override, ok := m.Config.RootLimits[rootID.String()]
+	if !ok {
+		return limits
+	}
+
+	if override.MaxDepth > 0 {
+		limits.MaxDepth = override.MaxDepth
+	}
+	if override.MaxChildren > 0 {
+		limits.MaxChildren = override.MaxChildren
+	}
+	limits.MaxItems = override.MaxItems
+
+	return limits
+}
+
+// checkLimits verifies that placing subtree under parent (the root level when nil)
+// keeps the group within its limits. The global max_items caps the whole group, a per
+// root max_items caps the tree under that root.
+func (m *MenuService) checkLimits(ctx context.Context, groupID uuid.UUID, parent *entity.MenuEntity, subtree subtreeShape) error {
+	var (
+		parentID *uuid.UUID
+		rootID   = subtree.ID
+		depth    = 0
+	)
+	if parent != nil {
+		parentID = &parent.ID
+		rootID = rootOf(parent.Path)
+		depth = parent.Depth + 1
+	}
+
+	limits := m.limitsFor(rootID)
+
+	// depth counts from 0, so a tree with max_depth levels ends at depth max_depth - 1
+	if limits.MaxDepth > 0 && depth+subtree.Height >= limits.MaxDepth {
+		return &LimitError{Limit: LimitMaxDepth, Max: limits.MaxDepth}
+	}
+
+	if limits.MaxChildren > 0 && !(subtree.Existing && sameParent(subtree.ParentID, parentID)) {
+		siblings, err := m.MenuRepoInterface.FindChildren(ctx, groupID, parentID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] checkLimits - 1")
+			return err
+		}
+		if len(siblings) >= limits.MaxChildren {
+			return &LimitError{Limit: LimitMaxChildren, Max: limits.MaxChildren}
+		}
+	}
+
+	if maxItems := m.Config.Limits.MaxItems; maxItems > 0 && !subtree.Existing {
+		count, err := m.MenuRepoInterface.CountMenus(ctx, groupID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] checkLimits - 2")
+			return err
+		}
+		if int(count)+subtree.Size > maxItems {
+			return &LimitError{Limit: LimitMaxItems, Max: maxItems}
+		}
+	}
+
+	if limits.MaxItems > 0 && !(subtree.Existing && subtree.RootID == rootID) {
+		size := subtree.Size
+		if rootID != subtree.ID {
+			count, err := m.MenuRepoInterface.CountDescendants(ctx, rootID)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] checkLimits - 3")
+				return err
+			}
+			size += int(count) + 1
+		}
+		if size > limits.MaxItems {
+			return &LimitError{Limit: LimitMaxItems, Max: limits.MaxItems}
+		}
+	}
+
+	return nil
+}
+
+// checkReparentLimits verifies that menus, the children the parent of menu (the root
+// level when nil) has once menu's own children replace it, stay within max_children.
+// Moving up a level never adds depth or items to the group, so only that limit is
+// checked.
+func (m *MenuService) checkReparentLimits(menu *entity.MenuEntity, menus []entity.MenuEntity) error {
+	if menu.MenuID != nil {
+		if maxChildren := m.limitsFor(rootOf(menu.Path)).MaxChildren; maxChildren > 0 && len(menus) > maxChildren {
+			return &LimitError{Limit: LimitMaxChildren, Max: maxChildren}
+		}
+		return nil
+	}
+
+	// the root level counts against the limits of each root, as on create
+	for _, root := range menus {
+		if maxChildren := m.limitsFor(root.ID).MaxChildren; maxChildren > 0 && len(menus) > maxChildren {
+			return &LimitError{Limit: LimitMaxChildren, Max: maxChildren}
+		}
+	}
+
+	return nil
+}
+
+// checkTreeLimits verifies a whole tree before it replaces the menus of a group. menus
+// is the tree flattened in pre-order, with parents and depths filled in, so it is
+// checked in memory against the same limits as checkLimits.
+func (m *MenuService) checkTreeLimits(menus []entity.MenuEntity) error {
+	if maxItems := m.Config.Limits.MaxItems; maxItems > 0 && len(menus) > maxItems {
+		return &LimitError{Limit: LimitMaxItems, Max: maxItems}
+	}
+
+	var (
+		rootIDs  = make(map[uuid.UUID]uuid.UUID, len(menus))
+		children = make(map[uuid.UUID]int, len(menus))
+		items    = make(map[uuid.UUID]int)
+		roots    []uuid.UUID
+	)
+
+	// a parent comes before its children, so its root is already known
+	for _, menu := range menus {
+		rootID := menu.ID
+		if menu.MenuID != nil {
+			rootID = rootIDs[*menu.MenuID]
+			children[*menu.MenuID]++
+		} else {
+			roots = append(roots, menu.ID)
+		}
+		rootIDs[menu.ID] = rootID
+		items[rootID]++
+	}
+
+	for _, menu := range menus {
+		limits := m.limitsFor(rootIDs[menu.ID])
+
+		if limits.MaxDepth > 0 && menu.Depth >= limits.MaxDepth {
+			return &LimitError{Limit: LimitMaxDepth, Max: limits.MaxDepth}
+		}
+		if limits.MaxChildren > 0 && children[menu.ID] > limits.MaxChildren {
+			return &LimitError{Limit: LimitMaxChildren, Max: limits.MaxChildren}
+		}
+		if menu.MenuID == nil && limits.MaxItems > 0 && items[menu.ID] > limits.MaxItems {
+			return &LimitError{Limit: LimitMaxItems, Max: limits.MaxItems}
+		}
+	}
+
+	// the root level counts against the limits of each root, as on create
+	for _, rootID := range roots {
+		if maxChildren := m.limitsFor(rootID).MaxChildren; maxChildren > 0 && len(roots) > maxChildren {
+			return &LimitError{Limit: LimitMaxChildren, Max: maxChildren}
+		}
+	}
+
+	return nil
+}
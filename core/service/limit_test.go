@@ -0,0 +1,174 @@
+package service
+
+import (
+	"errors"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// limitTree flattens a tree given as parent indexes, -1 for a root, the way
+// flattenMenuTree does: in pre-order with parents and depths filled in.
+func limitTree(ids []uuid.UUID, parents []int) []entity.MenuEntity {
+	menus := make([]entity.MenuEntity, len(parents))
+	for i, parent := range parents {
+		menus[i].ID = ids[i]
+		if parent >= 0 {
+			menus[i].MenuID = &menus[parent].ID
+			menus[i].Depth = menus[parent].Depth + 1
+		}
+	}
+	return menus
+}
+
+func TestCheckTreeLimits(t *testing.T) {
+	ids := make([]uuid.UUID, 6)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+
+	tests := []struct {
+		name    string
+		cfg     config.Menu
+		parents []int
+		limit   string
+	}{
+		{
+			name:    "no limits",
+			parents: []int{-1, 0, 1, 2, 3, 4},
+		},
+		{
+			name:    "within every limit",
+			cfg:     config.Menu{Limits: config.MenuLimit{MaxDepth: 3, MaxChildren: 2, MaxItems: 6}},
+			parents: []int{-1, 0, 1, 0, -1, 4},
+		},
+		{
+			name:    "too deep",
+			cfg:     config.Menu{Limits: config.MenuLimit{MaxDepth: 3}},
+			parents: []int{-1, 0, 1, 2},
+			limit:   LimitMaxDepth,
+		},
+		{
+			name:    "too many children",
+			cfg:     config.Menu{Limits: config.MenuLimit{MaxChildren: 2}},
+			parents: []int{-1, 0, 0, 0},
+			limit:   LimitMaxChildren,
+		},
+		{
+			name:    "too many roots",
+			cfg:     config.Menu{Limits: config.MenuLimit{MaxChildren: 2}},
+			parents: []int{-1, -1, -1},
+			limit:   LimitMaxChildren,
+		},
+		{
+			name:    "too many items in the group",
+			cfg:     config.Menu{Limits: config.MenuLimit{MaxItems: 3}},
+			parents: []int{-1, 0, -1, 2},
+			limit:   LimitMaxItems,
+		},
+		{
+			name: "too many items under a root",
+			cfg: config.Menu{RootLimits: map[string]config.MenuLimit{
+				ids[0].String(): {MaxItems: 2},
+			}},
+			parents: []int{-1, 0, 1, -1},
+			limit:   LimitMaxItems,
+		},
+		{
+			name: "deeper root override",
+			cfg: config.Menu{
+				Limits: config.MenuLimit{MaxDepth: 2},
+				RootLimits: map[string]config.MenuLimit{
+					ids[0].String(): {MaxDepth: 4},
+				},
+			},
+			parents: []int{-1, 0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MenuService{Config: tt.cfg}
+			err := m.checkTreeLimits(limitTree(ids, tt.parents))
+
+			var limitErr *LimitError
+			switch {
+			case tt.limit == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.limit != "" && !errors.As(err, &limitErr):
+				t.Fatalf("error = %v, want a %s limit error", err, tt.limit)
+			case tt.limit != "" && limitErr.Limit != tt.limit:
+				t.Fatalf("limit = %s, want %s", limitErr.Limit, tt.limit)
+			}
+		})
+	}
+}
+
+func TestCheckReparentLimits(t *testing.T) {
+	ids := make([]uuid.UUID, 4)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	root := entity.MenuEntity{ID: ids[0], Path: strings.ReplaceAll(ids[0].String(), "-", "")}
+	child := entity.MenuEntity{ID: ids[1], MenuID: &root.ID, Path: root.Path + "." + strings.ReplaceAll(ids[1].String(), "-", "")}
+
+	tests := []struct {
+		name  string
+		cfg   config.Menu
+		menu  entity.MenuEntity
+		after []entity.MenuEntity
+		limit string
+	}{
+		{
+			name:  "children fit under the parent",
+			cfg:   config.Menu{Limits: config.MenuLimit{MaxChildren: 2}},
+			menu:  child,
+			after: limitTree(ids, []int{-1, -1}),
+		},
+		{
+			name:  "too many children under the parent",
+			cfg:   config.Menu{Limits: config.MenuLimit{MaxChildren: 2}},
+			menu:  child,
+			after: limitTree(ids, []int{-1, -1, -1}),
+			limit: LimitMaxChildren,
+		},
+		{
+			name: "root override on the parent's tree",
+			cfg: config.Menu{
+				Limits: config.MenuLimit{MaxChildren: 2},
+				RootLimits: map[string]config.MenuLimit{
+					root.ID.String(): {MaxChildren: 3},
+				},
+			},
+			menu:  child,
+			after: limitTree(ids, []int{-1, -1, -1}),
+		},
+		{
+			name:  "too many roots",
+			cfg:   config.Menu{Limits: config.MenuLimit{MaxChildren: 3}},
+			menu:  root,
+			after: limitTree(ids, []int{-1, -1, -1, -1}),
+			limit: LimitMaxChildren,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &MenuService{Config: tt.cfg}
+			err := m.checkReparentLimits(&tt.menu, tt.after)
+
+			var limitErr *LimitError
+			switch {
+			case tt.limit == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tt.limit != "" && !errors.As(err, &limitErr):
+				t.Fatalf("error = %v, want a %s limit error", err, tt.limit)
+			case tt.limit != "" && limitErr.Limit != tt.limit:
+				t.Fatalf("limit = %s, want %s", limitErr.Limit, tt.limit)
+			}
+		})
+	}
+}
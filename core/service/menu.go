@@ -1,11 +1,17 @@
 package service
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"golang_menu_interview/config"
 	"golang_menu_interview/core/domain/entity"
 	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/menucache"
 	"golang_menu_interview/utils/treemenu"
+	"slices"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -13,84 +19,608 @@ import (
 
 type MenuServiceInterface interface {
 	CreateMenu(ctx context.Context, req entity.MenuEntity) error
-	FindAllMenu(ctx context.Context) ([]entity.MenuEntity, error)
-	FindMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error)
-	UpdateMenu(ctx context.Context, req entity.MenuEntity) error
-	DeleteMenu(ctx context.Context, id uuid.UUID) error
-	MoveMenu(ctx context.Context, req entity.MenuEntity) error
-	ReorderMenu(ctx context.Context, req entity.MenuEntity) error
+	FindAllMenu(ctx context.Context, groupID uuid.UUID, maxDepth *int, at *time.Time) ([]entity.MenuEntity, *time.Time, error)
+	FindMenuByID(ctx context.Context, id uuid.UUID, maxDepth *int) (*entity.MenuEntity, error)
+	FindAncestors(ctx context.Context, id uuid.UUID, includeSiblings bool, published bool) ([]entity.BreadcrumbEntity, error)
+	FindMenuList(ctx context.Context, req entity.MenuListEntity) ([]entity.MenuEntity, string, error)
+	SearchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, error)
+	SearchMenuTree(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuEntity, error)
+	UpdateMenu(ctx context.Context, req entity.UpdateMenuEntity) error
+	DeleteMenu(ctx context.Context, id uuid.UUID, strategy string) (int64, error)
+	MoveMenu(ctx context.Context, req entity.MoveMenuEntity) error
+	ReorderMenu(ctx context.Context, groupID uuid.UUID, req entity.MenuEntity) error
+	FindTrashedMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error)
+	RestoreMenu(ctx context.Context, id uuid.UUID) error
+	PurgeMenu(ctx context.Context, olderThan time.Duration) (int64, error)
+	ReplaceMenuTree(ctx context.Context, groupID uuid.UUID, tree []entity.MenuEntity) (*entity.MenuTreeDiffEntity, error)
+	ReorderChildren(ctx context.Context, groupID uuid.UUID, parentID *uuid.UUID, ids []uuid.UUID) error
+	FindAllRevision(ctx context.Context, groupID uuid.UUID, cursor string, limit int) ([]entity.MenuRevisionEntity, string, error)
+	FindAllMenuAsOf(ctx context.Context, groupID uuid.UUID, revisionID int64, at time.Time, maxDepth *int) ([]entity.MenuEntity, error)
+	RollbackRevision(ctx context.Context, revisionID int64) error
+	FindPublishedMenu(ctx context.Context, groupID uuid.UUID, maxDepth *int, at *time.Time) ([]entity.MenuEntity, *time.Time, error)
+	FindPublishedMenuByID(ctx context.Context, id uuid.UUID, maxDepth *int) (*entity.MenuEntity, error)
+	PublishMenu(ctx context.Context, groupID uuid.UUID) (*entity.MenuPublicationEntity, error)
+	DiscardDraft(ctx context.Context, groupID uuid.UUID) error
+	FindTranslations(ctx context.Context, menuID uuid.UUID, published bool) ([]entity.MenuTranslationEntity, error)
+	SaveTranslation(ctx context.Context, req entity.MenuTranslationEntity) error
+	DeleteTranslation(ctx context.Context, menuID uuid.UUID, locale string) error
 }
 
 type MenuService struct {
-	MenuRepoInterface repository.MenuRepositoryInterface
+	MenuRepoInterface            repository.MenuRepositoryInterface
+	MenuGroupRepoInterface       repository.MenuGroupRepositoryInterface
+	MenuRevisionRepoInterface    repository.MenuRevisionRepositoryInterface
+	MenuPublicationRepoInterface repository.MenuPublicationRepositoryInterface
+	MenuTranslationRepoInterface repository.MenuTranslationRepositoryInterface
+	AuditLogRepoInterface        repository.AuditLogRepositoryInterface
+	Cache                        *menucache.Cache
+	Config                       config.Menu
 }
 
-func NewMenuService(menuRepoInterface repository.MenuRepositoryInterface) MenuServiceInterface {
+func NewMenuService(menuRepoInterface repository.MenuRepositoryInterface, menuGroupRepoInterface repository.MenuGroupRepositoryInterface, menuRevisionRepoInterface repository.MenuRevisionRepositoryInterface, menuPublicationRepoInterface repository.MenuPublicationRepositoryInterface, menuTranslationRepoInterface repository.MenuTranslationRepositoryInterface, auditLogRepoInterface repository.AuditLogRepositoryInterface, cache *menucache.Cache, cfg config.Menu) MenuServiceInterface {
 	return &MenuService{
-		MenuRepoInterface: menuRepoInterface,
+		MenuRepoInterface:            menuRepoInterface,
+		MenuGroupRepoInterface:       menuGroupRepoInterface,
+		MenuRevisionRepoInterface:    menuRevisionRepoInterface,
+		MenuPublicationRepoInterface: menuPublicationRepoInterface,
+		MenuTranslationRepoInterface: menuTranslationRepoInterface,
+		AuditLogRepoInterface:        auditLogRepoInterface,
+		Cache:                        cache,
+		Config:                       cfg,
 	}
 }
 
+// transaction runs a change to the menus in one transaction and, once it is committed,
+// drops the cached trees. Every write of the service goes through it.
+func (m *MenuService) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := m.MenuRepoInterface.Transaction(ctx, fn); err != nil {
+		return err
+	}
+
+	m.Cache.Invalidate()
+	return nil
+}
+
 // CreateMenu implements MenuServiceInterface.
 func (m *MenuService) CreateMenu(ctx context.Context, req entity.MenuEntity) error {
 
-	if req.MenuID != nil {
-		parent, err := m.MenuRepoInterface.FindMenuByID(ctx, *req.MenuID)
-		if err != nil {
-			log.Err(err).Msg("[SERVICE] CreateMenu - 1 ")
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, req.GroupID); err != nil {
+		log.Err(err).Msg("[SERVICE] CreateMenu - 1 ")
+		return err
+	}
+
+	if err := checkVisibilityWindow(req); err != nil {
+		return err
+	}
+
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		// the limits are checked against the locked group and parent, so a concurrent
+		// write cannot pass the same check before this menu is counted
+		if err := m.MenuGroupRepoInterface.LockMenuGroup(ctx, req.GroupID); err != nil {
+			log.Err(err).Msg("[SERVICE] CreateMenu - 2 ")
+			return err
+		}
+
+		var parent *entity.MenuEntity
+		if req.MenuID != nil {
+			var err error
+			parent, err = m.MenuRepoInterface.LockMenu(ctx, *req.MenuID)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] CreateMenu - 3 ")
+				return err
+			}
+			if parent.GroupID != req.GroupID {
+				return errors.New("parent menu belongs to a different group")
+			}
+			if parent.Type == entity.MenuTypeSeparator {
+				return errors.New("separator menu cannot have children")
+			}
+			req.Depth = parent.Depth + 1
+		} else {
+			req.Depth = 0
+		}
+
+		if err := m.checkLimits(ctx, req.GroupID, parent, subtreeShape{ID: req.ID, Size: 1}); err != nil {
+			log.Err(err).Msg("[SERVICE] CreateMenu - 4 ")
 			return err
 		}
-		req.Depth = parent.Depth + 1
-	} else {
-		req.Depth = 0
-	}
 
-	return m.MenuRepoInterface.CreateMenu(ctx, req)
+		if err := m.MenuRepoInterface.CreateMenu(ctx, req); err != nil {
+			log.Err(err).Msg("[SERVICE] CreateMenu - 5 ")
+			return err
+		}
+
+		return m.recordRevision(ctx, req.GroupID, entity.RevisionCreate, &req.ID, nil, []uuid.UUID{req.ID})
+	})
 }
 
 // FindAllMenu implements MenuServiceInterface.
-func (m *MenuService) FindAllMenu(ctx context.Context) ([]entity.MenuEntity, error) {
-	menus, err := m.MenuRepoInterface.FindAllMenu(ctx)
-	if err != nil {
+// A non nil maxDepth stops the tree that many levels below the roots. Menus the
+// caller's claims do not permit are left out. The draft shows every other menu unless
+// at asks for a preview of what is visible at that time; the returned time is then
+// when that visibility next changes.
+func (m *MenuService) FindAllMenu(ctx context.Context, groupID uuid.UUID, maxDepth *int, at *time.Time) ([]entity.MenuEntity, *time.Time, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
 		log.Err(err).Msg("[SERVICE] GetAllMenus - 1")
-		return nil, err
+		return nil, nil, err
 	}
 
-	tree := treemenu.BuildTree(menus, nil)
-	return tree, nil
+	menus, err := m.MenuRepoInterface.FindSubtree(ctx, entity.MenuQueryEntity{GroupID: groupID, MaxDepth: maxDepth})
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] GetAllMenus - 2")
+		return nil, nil, err
+	}
+
+	return m.presentTree(ctx, menus, at, false)
+}
+
+// presentTree turns the menus of a tree read into the tree returned to the caller:
+// menus the claims on ctx do not permit are dropped, with those hidden at at when it
+// is set, and names are localized from the published translations when published is
+// set. It also returns when that visibility next changes.
+func (m *MenuService) presentTree(ctx context.Context, menus []entity.MenuEntity, at *time.Time, published bool) ([]entity.MenuEntity, *time.Time, error) {
+	menus = permitted(ctx, menus)
+
+	var nextChangeAt *time.Time
+	if at != nil {
+		menus, nextChangeAt = treemenu.Visible(menus, *at)
+	}
+
+	if err := m.localize(ctx, menus, published); err != nil {
+		log.Err(err).Msg("[SERVICE] presentTree - 1")
+		return nil, nil, err
+	}
+
+	result := treemenu.Build(menus, nil)
+	if len(result.Orphans) > 0 || len(result.Cycles) > 0 {
+		log.Warn().Interface("orphans", result.Orphans).Interface("cycles", result.Cycles).Msg("[SERVICE] presentTree - broken parent links")
+	}
+
+	return result.Tree, nextChangeAt, nil
 }
 
 // FindMenuByID implements MenuServiceInterface.
-func (m *MenuService) FindMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error) {
-	menu, err := m.MenuRepoInterface.FindMenuByID(ctx, id)
+// Only the subtree of the menu is read, down to maxDepth levels below it when set.
+// A menu the caller's claims do not permit, or whose ancestors they do not, is not
+// found; those below it are left out.
+func (m *MenuService) FindMenuByID(ctx context.Context, id uuid.UUID, maxDepth *int) (*entity.MenuEntity, error) {
+	subtree, err := m.MenuRepoInterface.FindSubtree(ctx, entity.MenuQueryEntity{ID: &id, MaxDepth: maxDepth})
 	if err != nil {
 		log.Err(err).Msg("[SERVICE] FindMenuByID - 1")
 		return nil, err
 	}
 
-	allMenus, err := m.MenuRepoInterface.FindAllMenu(ctx)
+	readable, err := m.readable(ctx, subtree, nil, m.MenuRepoInterface.FindMenusByIDs)
 	if err != nil {
 		log.Err(err).Msg("[SERVICE] FindMenuByID - 2")
 		return nil, err
 	}
+	subtree = slices.DeleteFunc(subtree, func(menu entity.MenuEntity) bool {
+		return !readable[menu.ID]
+	})
+
+	// rows come ordered by depth, so the menu itself is first
+	if len(subtree) == 0 || subtree[0].ID != id {
+		return nil, errors.New("menu not found")
+	}
+
+	if err := m.localize(ctx, subtree, false); err != nil {
+		log.Err(err).Msg("[SERVICE] FindMenuByID - 3")
+		return nil, err
+	}
+
+	menu := subtree[0]
+	menu.Children = treemenu.BuildTree(subtree[1:], &menu.ID)
+
+	return &menu, nil
+}
+
+// defaultMenuListLimit is the page size of the flat listing when none is given.
+const defaultMenuListLimit = 20
+
+// FindMenuList implements MenuServiceInterface.
+// It returns one page of menus and the cursor of the next page, empty on the last one.
+// Menus the caller's claims do not permit or that are hidden at the server time are
+// dropped from the page, so a page can hold fewer than req.Limit menus. The draft is
+// listed unless req.Published is set.
+func (m *MenuService) FindMenuList(ctx context.Context, req entity.MenuListEntity) ([]entity.MenuEntity, string, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, req.GroupID); err != nil {
+		log.Err(err).Msg("[SERVICE] FindMenuList - 1")
+		return nil, "", err
+	}
+
+	if req.Sort == "" {
+		req.Sort = entity.MenuSortOrder
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultMenuListLimit
+	}
+
+	if req.Cursor != "" {
+		after, err := decodeMenuCursor(req.Sort, req.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		req.After = after
+	}
+
+	// one extra row tells whether another page follows
+	limit := req.Limit
+	req.Limit++
+
+	menus, err := m.MenuRepoInterface.FindMenuList(ctx, req)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindMenuList - 2")
+		return nil, "", err
+	}
+
+	nextCursor := ""
+	if len(menus) > limit {
+		menus = menus[:limit]
+		nextCursor, err = encodeMenuCursor(req.Sort, menus[limit-1])
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] FindMenuList - 3")
+			return nil, "", err
+		}
+	}
+
+	now := time.Now().UTC()
+	readable, err := m.readable(ctx, menus, &now, m.menusByIDs(req.Published))
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindMenuList - 4")
+		return nil, "", err
+	}
+	menus = slices.DeleteFunc(menus, func(menu entity.MenuEntity) bool {
+		return !readable[menu.ID]
+	})
+
+	return menus, nextCursor, nil
+}
+
+// SearchMenu implements MenuServiceInterface.
+// Every hit carries the names and ids of its ancestors, all loaded in one query.
+func (m *MenuService) SearchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, error) {
+	hits, ancestors, err := m.searchMenu(ctx, groupID, q, limit, published)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]entity.MenuEntity, len(ancestors))
+	for _, ancestor := range ancestors {
+		byID[ancestor.ID] = ancestor
+	}
+
+	for i, hit := range hits {
+		pathIDs := repository.PathIDs(hit.Path)
+
+		hits[i].Ancestors = make([]entity.MenuPathEntity, 0, len(pathIDs))
+		for _, id := range pathIDs[:len(pathIDs)-1] {
+			hits[i].Ancestors = append(hits[i].Ancestors, entity.MenuPathEntity{ID: id, Name: byID[id].Name})
+		}
+	}
+
+	return hits, nil
+}
+
+// SearchMenuTree implements MenuServiceInterface.
+// It returns the tree pruned down to the hits and the ancestors leading to them.
+func (m *MenuService) SearchMenuTree(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuEntity, error) {
+	hits, ancestors, err := m.searchMenu(ctx, groupID, q, limit, published)
+	if err != nil {
+		return nil, err
+	}
+
+	menus := make([]entity.MenuEntity, 0, len(hits)+len(ancestors))
+	seen := make(map[uuid.UUID]bool, len(hits)+len(ancestors))
+	for _, hit := range hits {
+		menus = append(menus, hit.MenuEntity)
+		seen[hit.ID] = true
+	}
+	for _, ancestor := range ancestors {
+		if !seen[ancestor.ID] {
+			menus = append(menus, ancestor)
+		}
+	}
+
+	return treemenu.BuildTree(menus, nil), nil
+}
+
+// searchMenu returns the hits for q together with every ancestor of those hits. Hits
+// the caller's claims do not permit or that are hidden at the server time, themselves
+// or through an ancestor, are left out. The published menus are searched when
+// published is set, else the draft.
+func (m *MenuService) searchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, []entity.MenuEntity, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] SearchMenu - 1")
+		return nil, nil, err
+	}
+
+	if limit <= 0 {
+		limit = defaultMenuListLimit
+	}
+
+	hits, err := m.MenuRepoInterface.SearchMenu(ctx, groupID, q, limit, published)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] SearchMenu - 2")
+		return nil, nil, err
+	}
+
+	var ids []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, hit := range hits {
+		pathIDs := repository.PathIDs(hit.Path)
+		for _, id := range pathIDs[:len(pathIDs)-1] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	ancestors, err := m.menusByIDs(published)(ctx, ids)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] SearchMenu - 3")
+		return nil, nil, err
+	}
+
+	menus := slices.Clone(ancestors)
+	for _, hit := range hits {
+		menus = append(menus, hit.MenuEntity)
+	}
+
+	now := time.Now().UTC()
+	readable, err := m.readable(ctx, menus, &now, m.menusByIDs(published))
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] SearchMenu - 4")
+		return nil, nil, err
+	}
+
+	hits = slices.DeleteFunc(hits, func(hit entity.MenuSearchHitEntity) bool {
+		return !readable[hit.ID]
+	})
+	ancestors = slices.DeleteFunc(ancestors, func(menu entity.MenuEntity) bool {
+		return !readable[menu.ID]
+	})
+
+	return hits, ancestors, nil
+}
+
+// FindAncestors implements MenuServiceInterface.
+// The chain runs from the root down to the menu itself; with includeSiblings every
+// level also carries the menus sharing its parent, in display order. A menu the
+// caller may not see at the server time is not found, and hidden siblings are left
+// out. The chain is read from the published menus when published is set.
+func (m *MenuService) FindAncestors(ctx context.Context, id uuid.UUID, includeSiblings bool, published bool) ([]entity.BreadcrumbEntity, error) {
+	ancestors, err := m.MenuRepoInterface.FindAncestors(ctx, id, published)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindAncestors - 1")
+		return nil, err
+	}
+
+	var siblings []entity.MenuEntity
+	if includeSiblings {
+		siblings, err = m.MenuRepoInterface.FindAncestorSiblings(ctx, id, published)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] FindAncestors - 2")
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	readable, err := m.readable(ctx, append(slices.Clone(ancestors), siblings...), &now, m.menusByIDs(published))
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindAncestors - 3")
+		return nil, err
+	}
+	if !readable[id] {
+		return nil, errors.New("menu not found")
+	}
+
+	breadcrumbs := make([]entity.BreadcrumbEntity, 0, len(ancestors))
+	for _, ancestor := range ancestors {
+		breadcrumbs = append(breadcrumbs, entity.BreadcrumbEntity{MenuEntity: ancestor})
+	}
 
-	menu.Children = treemenu.BuildTree(allMenus, &menu.ID)
+	if !includeSiblings {
+		return breadcrumbs, nil
+	}
+
+	// the chain holds one menu per depth, so a sibling's depth is its level
+	for _, sibling := range siblings {
+		if readable[sibling.ID] && sibling.Depth < len(breadcrumbs) {
+			breadcrumbs[sibling.Depth].Siblings = append(breadcrumbs[sibling.Depth].Siblings, sibling)
+		}
+	}
 
-	return menu, nil
+	return breadcrumbs, nil
 }
 
 // UpdateMenu implements MenuServiceInterface.
-func (m *MenuService) UpdateMenu(ctx context.Context, req entity.MenuEntity) error {
-	return m.MenuRepoInterface.UpdateMenu(ctx, req)
+// req.Version must still be the version of the menu, else a *VersionConflictError
+// carrying the current menu is returned. An empty req.Type keeps the type of the menu
+// along with the link fields req leaves empty, and the fields req does not set keep
+// their values.
+func (m *MenuService) UpdateMenu(ctx context.Context, update entity.UpdateMenuEntity) error {
+	req := update.MenuEntity
+
+	currentMenu, err := m.MenuRepoInterface.FindMenuByID(ctx, req.ID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] UpdateMenu - 1")
+		return err
+	}
+
+	if err := checkVersion(currentMenu, req.Version); err != nil {
+		return err
+	}
+
+	if !update.SortOrderSet {
+		req.SortOrder = currentMenu.SortOrder
+	}
+	if !update.VisibleFromSet {
+		req.VisibleFrom = currentMenu.VisibleFrom
+	}
+	if !update.VisibleUntilSet {
+		req.VisibleUntil = currentMenu.VisibleUntil
+	}
+	if !update.RequiredRolesSet {
+		req.RequiredRoles = currentMenu.RequiredRoles
+	}
+	if !update.RequiredPermissionsSet {
+		req.RequiredPermissions = currentMenu.RequiredPermissions
+	}
+
+	if err := checkVisibilityWindow(req); err != nil {
+		return err
+	}
+
+	if req.Type == "" {
+		req.Type = currentMenu.Type
+		req.URL = cmp.Or(req.URL, currentMenu.URL)
+		req.RouteName = cmp.Or(req.RouteName, currentMenu.RouteName)
+		req.Icon = cmp.Or(req.Icon, currentMenu.Icon)
+		req.Target = cmp.Or(req.Target, currentMenu.Target)
+		req.Rel = cmp.Or(req.Rel, currentMenu.Rel)
+
+		if err := checkTypeFields(req); err != nil {
+			return err
+		}
+	}
+
+	if req.Type == entity.MenuTypeSeparator {
+		count, err := m.MenuRepoInterface.CountChildren(ctx, req.ID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] UpdateMenu - 2")
+			return err
+		}
+		if count > 0 {
+			return errors.New("menu with children cannot become a separator")
+		}
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		if err := m.lockVersion(ctx, req.ID, req.Version); err != nil {
+			return err
+		}
+
+		if err := m.MenuRepoInterface.UpdateMenu(ctx, req); err != nil {
+			log.Err(err).Msg("[SERVICE] UpdateMenu - 3")
+			return err
+		}
+
+		return m.recordRevision(ctx, currentMenu.GroupID, entity.RevisionUpdate, &req.ID, currentMenu, []uuid.UUID{req.ID})
+	})
 }
 
 // DeleteMenu implements MenuServiceInterface.
-func (m *MenuService) DeleteMenu(ctx context.Context, id uuid.UUID) error {
-	return m.MenuRepoInterface.DeleteMenu(ctx, id)
+// It returns how many descendants were affected by the chosen strategy.
+func (m *MenuService) DeleteMenu(ctx context.Context, id uuid.UUID, strategy string) (int64, error) {
+	currentMenu, err := m.MenuRepoInterface.FindMenuByID(ctx, id)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] DeleteMenu - 1")
+		return 0, err
+	}
+
+	var affected int64
+	err = m.transaction(ctx, func(ctx context.Context) error {
+		affected, err = m.MenuRepoInterface.CountDescendants(ctx, id)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] DeleteMenu - 2")
+			return err
+		}
+
+		// the descendants are trashed along or moved up, either way they change
+		touched, err := m.MenuRepoInterface.FindSubtreeIDs(ctx, id)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] DeleteMenu - 3")
+			return err
+		}
+
+		switch strategy {
+		case entity.DeleteStrategyReject:
+			if affected > 0 {
+				return errors.New("menu has children")
+			}
+
+		case entity.DeleteStrategyReparent:
+			if affected > 0 {
+				siblings, err := m.reparentChildren(ctx, currentMenu)
+				if err != nil {
+					return err
+				}
+				touched = append(touched, siblings...)
+			}
+		}
+
+		if _, err := m.MenuRepoInterface.DeleteMenu(ctx, id); err != nil {
+			log.Err(err).Msg("[SERVICE] DeleteMenu - 4")
+			return err
+		}
+
+		return m.recordRevision(ctx, currentMenu.GroupID, entity.RevisionDelete, &id, currentMenu, touched)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return affected, nil
+}
+
+// reparentChildren hands the children of menu over to its parent, checking first that
+// they fit there, and numbers them after the parent's last child. It returns the ids
+// of the menus that now share the parent.
+func (m *MenuService) reparentChildren(ctx context.Context, menu *entity.MenuEntity) ([]uuid.UUID, error) {
+	// like on create and move, the limit is checked with the group locked
+	if err := m.MenuGroupRepoInterface.LockMenuGroup(ctx, menu.GroupID); err != nil {
+		log.Err(err).Msg("[SERVICE] reparentChildren - 1")
+		return nil, err
+	}
+
+	siblings, err := m.MenuRepoInterface.FindChildren(ctx, menu.GroupID, menu.MenuID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] reparentChildren - 2")
+		return nil, err
+	}
+
+	children, err := m.MenuRepoInterface.FindChildren(ctx, menu.GroupID, &menu.ID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] reparentChildren - 3")
+		return nil, err
+	}
+
+	siblings = slices.DeleteFunc(siblings, func(sibling entity.MenuEntity) bool {
+		return sibling.ID == menu.ID
+	})
+	if err := m.checkReparentLimits(menu, slices.Concat(siblings, children)); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.MenuRepoInterface.ReparentChildren(ctx, menu.ID, menu.MenuID); err != nil {
+		log.Err(err).Msg("[SERVICE] reparentChildren - 4")
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(siblings)+len(children))
+	for _, sibling := range slices.Concat(siblings, children) {
+		ids = append(ids, sibling.ID)
+	}
+
+	if err := m.MenuRepoInterface.UpdateSortOrders(ctx, ids); err != nil {
+		log.Err(err).Msg("[SERVICE] reparentChildren - 5")
+		return nil, err
+	}
+
+	return ids, nil
 }
 
 // MoveMenu implements MenuServiceInterface.
-func (m *MenuService) MoveMenu(ctx context.Context, req entity.MenuEntity) error {
+// The menu is placed before/after an anchor sibling or at a position among the new
+// siblings (appended when no anchor is given); both sibling lists are renumbered.
+// Like UpdateMenu it is refused when req.Version is no longer current.
+func (m *MenuService) MoveMenu(ctx context.Context, req entity.MoveMenuEntity) error {
 
 	currentMenu, err := m.MenuRepoInterface.FindMenuByID(ctx, req.ID)
 	if err != nil {
@@ -98,55 +628,659 @@ func (m *MenuService) MoveMenu(ctx context.Context, req entity.MenuEntity) error
 		return err
 	}
 
-	var newDepth int
-	if req.MenuID != nil {
+	if err := checkVersion(currentMenu, req.Version); err != nil {
+		return err
+	}
 
-		parent, err := m.MenuRepoInterface.FindMenuByID(ctx, *req.MenuID)
-		if err != nil {
+	return m.transaction(ctx, func(ctx context.Context) error {
+		// the limits are checked against the locked group and parent, so a concurrent
+		// write cannot pass the same check before this move is counted
+		if err := m.MenuGroupRepoInterface.LockMenuGroup(ctx, currentMenu.GroupID); err != nil {
 			log.Err(err).Msg("[SERVICE] MoveMenu - 2")
 			return err
 		}
 
-		isDesc, err := m.MenuRepoInterface.IsDescendant(ctx, parent.ID, req.ID)
+		if err := m.lockVersion(ctx, req.ID, req.Version); err != nil {
+			return err
+		}
+
+		var (
+			newDepth int
+			parent   *entity.MenuEntity
+		)
+		if req.MenuID != nil {
+			parent, err = m.MenuRepoInterface.LockMenu(ctx, *req.MenuID)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] MoveMenu - 3")
+				return err
+			}
+
+			if parent.GroupID != currentMenu.GroupID {
+				return errors.New("cannot move menu to a different group")
+			}
+
+			if parent.Type == entity.MenuTypeSeparator {
+				return errors.New("separator menu cannot have children")
+			}
+
+			isDesc, err := m.MenuRepoInterface.IsDescendant(ctx, parent.ID, req.ID)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] MoveMenu - 4")
+				return err
+			}
+			if isDesc {
+				return errors.New("cannot move menu to its own descendant")
+			}
+
+			newDepth = parent.Depth + 1
+		} else {
+
+			newDepth = 0
+		}
+
+		height, err := m.MenuRepoInterface.SubtreeHeight(ctx, req.ID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 5")
+			return err
+		}
+
+		descendants, err := m.MenuRepoInterface.CountDescendants(ctx, req.ID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 6")
+			return err
+		}
+
+		subtree := subtreeShape{
+			ID:       req.ID,
+			Existing: true,
+			ParentID: currentMenu.MenuID,
+			RootID:   rootOf(currentMenu.Path),
+			Height:   height,
+			Size:     int(descendants) + 1,
+		}
+		if err := m.checkLimits(ctx, currentMenu.GroupID, parent, subtree); err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 7")
+			return err
+		}
+
+		siblings, err := m.MenuRepoInterface.FindChildren(ctx, currentMenu.GroupID, req.MenuID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 8")
+			return err
+		}
+
+		order := make([]uuid.UUID, 0, len(siblings)+1)
+		for _, sibling := range siblings {
+			if sibling.ID != req.ID {
+				order = append(order, sibling.ID)
+			}
+		}
+
+		index, err := anchorIndex(order, req)
 		if err != nil {
-			log.Err(err).Msg("[SERVICE] MoveMenu - 3")
 			return err
 		}
-		if isDesc {
-			return errors.New("cannot move menu to its own descendant")
+		order = append(order[:index], append([]uuid.UUID{req.ID}, order[index:]...)...)
+
+		before := *currentMenu
+		oldParentID := currentMenu.MenuID
+		currentMenu.MenuID = req.MenuID
+		currentMenu.Depth = newDepth
+		if err := m.MenuRepoInterface.MoveMenu(ctx, *currentMenu); err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 9")
+			return err
+		}
+
+		if err := m.MenuRepoInterface.UpdateSortOrders(ctx, order); err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 10")
+			return err
 		}
 
-		newDepth = parent.Depth + 1
-	} else {
+		// the subtree follows the menu and both sibling lists are renumbered
+		touched := order
 
-		newDepth = 0
+		if !sameParent(oldParentID, req.MenuID) {
+			oldSiblings, err := m.MenuRepoInterface.FindChildren(ctx, currentMenu.GroupID, oldParentID)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] MoveMenu - 11")
+				return err
+			}
+
+			oldOrder := make([]uuid.UUID, 0, len(oldSiblings))
+			for _, sibling := range oldSiblings {
+				oldOrder = append(oldOrder, sibling.ID)
+			}
+
+			if err := m.MenuRepoInterface.UpdateSortOrders(ctx, oldOrder); err != nil {
+				log.Err(err).Msg("[SERVICE] MoveMenu - 12")
+				return err
+			}
+			touched = append(touched, oldOrder...)
+		}
+
+		subtreeIDs, err := m.MenuRepoInterface.FindSubtreeIDs(ctx, req.ID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] MoveMenu - 13")
+			return err
+		}
+		touched = append(touched, subtreeIDs...)
+
+		return m.recordRevision(ctx, currentMenu.GroupID, entity.RevisionMove, &req.ID, &before, touched)
+	})
+}
+
+// anchorIndex returns where the moved menu goes in order, which holds its future
+// siblings without the menu itself.
+func anchorIndex(order []uuid.UUID, req entity.MoveMenuEntity) (int, error) {
+	indexOf := func(id uuid.UUID) int {
+		for i, sibling := range order {
+			if sibling == id {
+				return i
+			}
+		}
+		return -1
 	}
 
-	depthDiff := newDepth - currentMenu.Depth
+	switch {
+	case req.BeforeID != nil:
+		index := indexOf(*req.BeforeID)
+		if index < 0 {
+			return 0, errors.New("anchor menu is not a child of the target parent")
+		}
+		return index, nil
+
+	case req.AfterID != nil:
+		index := indexOf(*req.AfterID)
+		if index < 0 {
+			return 0, errors.New("anchor menu is not a child of the target parent")
+		}
+		return index + 1, nil
 
-	req.Depth = newDepth
-	if err := m.MenuRepoInterface.MoveMenu(ctx, req); err != nil {
-		log.Err(err).Msg("[SERVICE] MoveMenu - 4")
+	case req.Position != nil:
+		return min(*req.Position, len(order)), nil
+	}
+
+	return len(order), nil
+}
+
+// ReorderMenu implements MenuServiceInterface.
+// The menu has to belong to groupID. Like UpdateMenu it is refused when req.Version is
+// no longer current.
+func (m *MenuService) ReorderMenu(ctx context.Context, groupID uuid.UUID, req entity.MenuEntity) error {
+
+	currentMenu, err := m.MenuRepoInterface.FindMenuByID(ctx, req.ID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] ReorderMenu - 1")
 		return err
 	}
 
-	if depthDiff != 0 {
-		if err := m.MenuRepoInterface.UpdateDescendantsDepth(ctx, req.ID, depthDiff); err != nil {
-			log.Err(err).Msg("[SERVICE] MoveMenu - 5")
+	if currentMenu.GroupID != groupID {
+		return errors.New("menu does not belong to this group")
+	}
+
+	if err := checkVersion(currentMenu, req.Version); err != nil {
+		return err
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		if err := m.lockVersion(ctx, req.ID, req.Version); err != nil {
+			return err
+		}
+
+		if err := m.MenuRepoInterface.ReorderMenu(ctx, groupID, req); err != nil {
+			log.Err(err).Msg("[SERVICE] ReorderMenu - 2")
+			return err
+		}
+
+		return m.recordRevision(ctx, currentMenu.GroupID, entity.RevisionReorder, &req.ID, currentMenu, []uuid.UUID{req.ID})
+	})
+}
+
+// ReorderChildren implements MenuServiceInterface.
+// ids must be exactly the current children of parentID (or the roots of groupID when
+// parentID is nil); their sort_order is rewritten to match the list.
+func (m *MenuService) ReorderChildren(ctx context.Context, groupID uuid.UUID, parentID *uuid.UUID, ids []uuid.UUID) error {
+	if parentID != nil {
+		parent, err := m.MenuRepoInterface.FindMenuByID(ctx, *parentID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] ReorderChildren - 1")
 			return err
 		}
+		groupID = parent.GroupID
+	} else if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] ReorderChildren - 2")
+		return err
 	}
 
-	return nil
+	return m.transaction(ctx, func(ctx context.Context) error {
+		children, err := m.MenuRepoInterface.FindChildren(ctx, groupID, parentID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] ReorderChildren - 3")
+			return err
+		}
+
+		if !sameIDSet(children, ids) {
+			return errors.New("ids must list exactly the current children")
+		}
+
+		previous, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] ReorderChildren - 4")
+			return err
+		}
+
+		if err := m.MenuRepoInterface.UpdateSortOrders(ctx, ids); err != nil {
+			log.Err(err).Msg("[SERVICE] ReorderChildren - 5")
+			return err
+		}
+
+		return m.recordTreeRevision(ctx, groupID, entity.RevisionReorderChildren, previous)
+	})
 }
 
-func (m *MenuService) ReorderMenu(ctx context.Context, req entity.MenuEntity) error {
+// sameIDSet reports whether ids holds every menu exactly once and nothing else.
+func sameIDSet(menus []entity.MenuEntity, ids []uuid.UUID) bool {
+	if len(menus) != len(ids) {
+		return false
+	}
+
+	remaining := make(map[uuid.UUID]bool, len(menus))
+	for _, menu := range menus {
+		remaining[menu.ID] = true
+	}
 
-	_, err := m.MenuRepoInterface.FindMenuByID(ctx, req.ID)
+	for _, id := range ids {
+		if !remaining[id] {
+			return false
+		}
+		delete(remaining, id)
+	}
+
+	return true
+}
+
+// FindTrashedMenu implements MenuServiceInterface.
+// Menus the caller's claims do not permit, or whose live ancestors they do not, are
+// left out.
+func (m *MenuService) FindTrashedMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] FindTrashedMenu - 1")
+		return nil, err
+	}
+
+	trashed, err := m.MenuRepoInterface.FindTrashedMenu(ctx, groupID)
 	if err != nil {
-		log.Err(err).Msg("[SERVICE] ReorderMenu - 1")
+		log.Err(err).Msg("[SERVICE] FindTrashedMenu - 2")
+		return nil, err
+	}
+
+	readable, err := m.readable(ctx, trashed, nil, m.MenuRepoInterface.FindMenusByIDs)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindTrashedMenu - 3")
+		return nil, err
+	}
+
+	return slices.DeleteFunc(trashed, func(menu entity.MenuEntity) bool {
+		return !readable[menu.ID]
+	}), nil
+}
+
+// RestoreMenu implements MenuServiceInterface.
+// The subtree goes back under its old parent, or to the root when that parent is gone.
+func (m *MenuService) RestoreMenu(ctx context.Context, id uuid.UUID) error {
+	trashed, err := m.MenuRepoInterface.FindTrashedMenuByID(ctx, id)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] RestoreMenu - 1")
 		return err
 	}
 
-	return m.MenuRepoInterface.ReorderMenu(ctx, req)
+	before := *trashed
+
+	var newDepth int
+	if trashed.MenuID != nil {
+		parent, err := m.MenuRepoInterface.FindMenuByID(ctx, *trashed.MenuID)
+		if err != nil && err.Error() != "menu not found" {
+			log.Err(err).Msg("[SERVICE] RestoreMenu - 2")
+			return err
+		}
+
+		if parent != nil && parent.Type != entity.MenuTypeSeparator {
+			newDepth = parent.Depth + 1
+		} else {
+			trashed.MenuID = nil
+		}
+	}
+
+	trashed.Depth = newDepth
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		touched, err := m.MenuRepoInterface.FindSubtreeIDs(ctx, id)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] RestoreMenu - 3")
+			return err
+		}
+
+		if err := m.MenuRepoInterface.RestoreMenu(ctx, *trashed); err != nil {
+			log.Err(err).Msg("[SERVICE] RestoreMenu - 4")
+			return err
+		}
+
+		return m.recordRevision(ctx, trashed.GroupID, entity.RevisionRestore, &id, &before, touched)
+	})
+}
+
+// PurgeMenu implements MenuServiceInterface.
+// Every menu deleted for good is audited along with its last state.
+func (m *MenuService) PurgeMenu(ctx context.Context, olderThan time.Duration) (int64, error) {
+	var purged []entity.MenuEntity
+
+	err := m.transaction(ctx, func(ctx context.Context) error {
+		var err error
+		purged, err = m.MenuRepoInterface.PurgeMenu(ctx, time.Now().Add(-olderThan))
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] PurgeMenu - 1")
+			return err
+		}
+
+		for i := range purged {
+			changes, err := diffMenu(&purged[i], nil)
+			if err != nil {
+				log.Err(err).Msg("[SERVICE] PurgeMenu - 2")
+				return err
+			}
+
+			if err := m.audit(ctx, entity.AuditPurge, purged[i].GroupID, entity.AuditTargetMenu, purged[i].ID, changes); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(purged)), nil
+}
+
+// ReplaceMenuTree implements MenuServiceInterface.
+// The submitted tree becomes the new state of the group: unknown items are created,
+// changed ones updated, moved or reordered, and stored items missing from it are
+// moved to the trash. A tree breaking the menu limits is refused with a *LimitError.
+// Everything is applied in one transaction.
+func (m *MenuService) ReplaceMenuTree(ctx context.Context, groupID uuid.UUID, tree []entity.MenuEntity) (*entity.MenuTreeDiffEntity, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 1")
+		return nil, err
+	}
+
+	stored, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 2")
+		return nil, err
+	}
+
+	storedByID := make(map[uuid.UUID]entity.MenuEntity, len(stored))
+	for _, menu := range stored {
+		storedByID[menu.ID] = menu
+	}
+
+	desired, err := flattenMenuTree(tree, groupID, storedByID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.checkTreeLimits(desired); err != nil {
+		return nil, err
+	}
+
+	diff := &entity.MenuTreeDiffEntity{
+		Created:   []uuid.UUID{},
+		Updated:   []uuid.UUID{},
+		Moved:     []uuid.UUID{},
+		Reordered: []uuid.UUID{},
+		Deleted:   []uuid.UUID{},
+	}
+
+	err = m.transaction(ctx, func(ctx context.Context) error {
+		// desired is in pre-order, so a parent is always in place before its children
+		kept := make(map[uuid.UUID]bool, len(desired))
+		for _, menu := range desired {
+			kept[menu.ID] = true
+
+			current, ok := storedByID[menu.ID]
+			if !ok {
+				if err := m.MenuRepoInterface.CreateMenu(ctx, menu); err != nil {
+					log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 3")
+					return err
+				}
+				diff.Created = append(diff.Created, menu.ID)
+				continue
+			}
+
+			contentChanged := !sameMenuContent(current, menu)
+			parentChanged := !sameParent(current.MenuID, menu.MenuID)
+			sortChanged := current.SortOrder != menu.SortOrder
+
+			if contentChanged {
+				if err := m.MenuRepoInterface.UpdateMenu(ctx, menu); err != nil {
+					log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 4")
+					return err
+				}
+				diff.Updated = append(diff.Updated, menu.ID)
+			}
+
+			// descendants follow their moved ancestor, so only a new parent needs a move
+			if parentChanged {
+				if err := m.MenuRepoInterface.MoveMenu(ctx, menu); err != nil {
+					log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 5")
+					return err
+				}
+				diff.Moved = append(diff.Moved, menu.ID)
+			}
+
+			if sortChanged && !contentChanged {
+				if err := m.MenuRepoInterface.ReorderMenu(ctx, groupID, menu); err != nil {
+					log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 6")
+					return err
+				}
+			}
+			if sortChanged && !parentChanged {
+				diff.Reordered = append(diff.Reordered, menu.ID)
+			}
+		}
+
+		for _, menu := range stored {
+			if kept[menu.ID] {
+				continue
+			}
+			diff.Deleted = append(diff.Deleted, menu.ID)
+
+			// a missing child of a missing parent is trashed together with that parent
+			if menu.MenuID != nil && !kept[*menu.MenuID] {
+				continue
+			}
+			if _, err := m.MenuRepoInterface.DeleteMenu(ctx, menu.ID); err != nil {
+				log.Err(err).Msg("[SERVICE] ReplaceMenuTree - 7")
+				return err
+			}
+		}
+
+		return m.recordTreeRevision(ctx, groupID, entity.RevisionReplaceTree, stored)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return diff, nil
+}
+
+// flattenMenuTree walks the submitted tree in pre-order and returns every node with
+// its group, parent, depth and sort order filled in from its position.
+func flattenMenuTree(tree []entity.MenuEntity, groupID uuid.UUID, storedByID map[uuid.UUID]entity.MenuEntity) ([]entity.MenuEntity, error) {
+	var (
+		result []entity.MenuEntity
+		seen   = map[uuid.UUID]bool{}
+		walk   func(nodes []entity.MenuEntity, parent *entity.MenuEntity) error
+	)
+
+	walk = func(nodes []entity.MenuEntity, parent *entity.MenuEntity) error {
+		for i, node := range nodes {
+			if node.ID == uuid.Nil {
+				node.ID = uuid.New()
+			} else if _, ok := storedByID[node.ID]; !ok {
+				return errors.New("menu " + node.ID.String() + " does not belong to this group")
+			}
+
+			if err := checkVisibilityWindow(node); err != nil {
+				return err
+			}
+
+			if seen[node.ID] {
+				return errors.New("menu " + node.ID.String() + " appears more than once in the tree")
+			}
+			seen[node.ID] = true
+
+			node.GroupID = groupID
+			node.SortOrder = i
+			if parent != nil {
+				if parent.Type == entity.MenuTypeSeparator {
+					return errors.New("separator menu cannot have children")
+				}
+				parentID := parent.ID
+				node.MenuID = &parentID
+				node.Depth = parent.Depth + 1
+			} else {
+				node.MenuID = nil
+				node.Depth = 0
+			}
+
+			children := node.Children
+			node.Children = nil
+			result = append(result, node)
+
+			if err := walk(children, &node); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(tree, nil); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func sameMenuContent(a, b entity.MenuEntity) bool {
+	return a.Name == b.Name && a.Type == b.Type && a.URL == b.URL && a.RouteName == b.RouteName &&
+		a.Icon == b.Icon && a.Target == b.Target && a.Rel == b.Rel &&
+		sameTime(a.VisibleFrom, b.VisibleFrom) && sameTime(a.VisibleUntil, b.VisibleUntil) &&
+		slices.Equal(a.RequiredRoles, b.RequiredRoles) && slices.Equal(a.RequiredPermissions, b.RequiredPermissions)
+}
+
+// permitted drops the menus, with their subtrees, that the claims on ctx do not meet
+// the requirements of. Admins see every menu.
+func permitted(ctx context.Context, menus []entity.MenuEntity) []entity.MenuEntity {
+	caller := claims.FromContext(ctx)
+	if caller.HasRole(claims.RoleAdmin) {
+		return menus
+	}
+	return treemenu.Permitted(menus, caller.Roles, caller.Permissions)
+}
+
+// readable returns the ids among menus that the claims on ctx permit and, when at is
+// set, that are visible at at. A menu only passes when its ancestors do as well, so
+// the ancestors missing from menus are loaded with find first.
+func (m *MenuService) readable(ctx context.Context, menus []entity.MenuEntity, at *time.Time, find func(context.Context, []uuid.UUID) ([]entity.MenuEntity, error)) (map[uuid.UUID]bool, error) {
+	kept := make(map[uuid.UUID]bool, len(menus))
+	if at == nil && claims.FromContext(ctx).HasRole(claims.RoleAdmin) {
+		for _, menu := range menus {
+			kept[menu.ID] = true
+		}
+		return kept, nil
+	}
+
+	seen := make(map[uuid.UUID]bool, len(menus))
+	for _, menu := range menus {
+		seen[menu.ID] = true
+	}
+
+	var missing []uuid.UUID
+	for _, menu := range menus {
+		for _, id := range repository.PathIDs(menu.Path) {
+			if !seen[id] {
+				seen[id] = true
+				missing = append(missing, id)
+			}
+		}
+	}
+
+	ancestors, err := find(ctx, missing)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] readable - 1")
+		return nil, err
+	}
+
+	shown := permitted(ctx, append(slices.Clone(menus), ancestors...))
+	if at != nil {
+		shown, _ = treemenu.Visible(shown, *at)
+	}
+
+	for _, menu := range shown {
+		kept[menu.ID] = true
+	}
+
+	return kept, nil
+}
+
+// menusByIDs returns the read loading menus by id from the published menus when
+// published is set, else from the draft.
+func (m *MenuService) menusByIDs(published bool) func(context.Context, []uuid.UUID) ([]entity.MenuEntity, error) {
+	if published {
+		return m.MenuRepoInterface.FindPublishedMenusByIDs
+	}
+	return m.MenuRepoInterface.FindMenusByIDs
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// checkTypeFields applies the per type rules of request.MenuRequest to menu, for
+// updates that left the type to the stored one.
+func checkTypeFields(menu entity.MenuEntity) error {
+	switch {
+	case menu.Type == entity.MenuTypeExternal && menu.URL == "":
+		return errors.New("url is required for an external menu")
+	case menu.Type == entity.MenuTypeInternal && menu.RouteName == "":
+		return errors.New("route_name is required for an internal menu")
+	case menu.Type != entity.MenuTypeExternal && menu.URL != "":
+		return errors.New("url is only allowed on an external menu")
+	case menu.Type != entity.MenuTypeInternal && menu.RouteName != "":
+		return errors.New("route_name is only allowed on an internal menu")
+	case menu.Type == entity.MenuTypeSeparator && menu.Icon != "":
+		return errors.New("icon is not allowed on a separator menu")
+	case (menu.Type == entity.MenuTypeHeader || menu.Type == entity.MenuTypeSeparator) && (menu.Target != "" || menu.Rel != ""):
+		return errors.New("target and rel are not allowed on a header or separator menu")
+	}
+	return nil
+}
+
+func checkVisibilityWindow(menu entity.MenuEntity) error {
+	if menu.VisibleFrom != nil && menu.VisibleUntil != nil && !menu.VisibleFrom.Before(*menu.VisibleUntil) {
+		return errors.New("visible_until must be after visible_from")
+	}
+	return nil
+}
+
+func sameParent(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
 }
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/internal/adapter/repository"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type MenuGroupServiceInterface interface {
+	CreateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error
+	FindAllMenuGroup(ctx context.Context) ([]entity.MenuGroupEntity, error)
+	FindMenuGroupByID(ctx context.Context, id uuid.UUID) (*entity.MenuGroupEntity, error)
+	UpdateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error
+	DeleteMenuGroup(ctx context.Context, id uuid.UUID) error
+}
+
+type MenuGroupService struct {
+	MenuGroupRepoInterface repository.MenuGroupRepositoryInterface
+}
+
+func NewMenuGroupService(menuGroupRepoInterface repository.MenuGroupRepositoryInterface) MenuGroupServiceInterface {
+	return &MenuGroupService{
+		MenuGroupRepoInterface: menuGroupRepoInterface,
+	}
+}
+
+// CreateMenuGroup implements MenuGroupServiceInterface.
+func (m *MenuGroupService) CreateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error {
+	return m.MenuGroupRepoInterface.CreateMenuGroup(ctx, req)
+}
+
+// FindAllMenuGroup implements MenuGroupServiceInterface.
+func (m *MenuGroupService) FindAllMenuGroup(ctx context.Context) ([]entity.MenuGroupEntity, error) {
+	return m.MenuGroupRepoInterface.FindAllMenuGroup(ctx)
+}
+
+// FindMenuGroupByID implements MenuGroupServiceInterface.
+func (m *MenuGroupService) FindMenuGroupByID(ctx context.Context, id uuid.UUID) (*entity.MenuGroupEntity, error) {
+	return m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, id)
+}
+
+// UpdateMenuGroup implements MenuGroupServiceInterface.
+func (m *MenuGroupService) UpdateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error {
+	return m.MenuGroupRepoInterface.UpdateMenuGroup(ctx, req)
+}
+
+// DeleteMenuGroup implements MenuGroupServiceInterface.
+// A group that still owns menus is refused so a navigation is never dropped by accident.
+func (m *MenuGroupService) DeleteMenuGroup(ctx context.Context, id uuid.UUID) error {
+	count, err := m.MenuGroupRepoInterface.CountMenus(ctx, id)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] DeleteMenuGroup - 1")
+		return err
+	}
+
+	if count > 0 {
+		return errors.New("menu group still has menus")
+	}
+
+	return m.MenuGroupRepoInterface.DeleteMenuGroup(ctx, id)
+}
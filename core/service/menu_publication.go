@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/utils/actor"
+	"golang_menu_interview/utils/treemenu"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// FindPublishedMenu implements MenuServiceInterface.
+// Only menus the caller is permitted and that are visible at at (the server time when
+// nil) are returned, along with when that visibility next changes. A non nil maxDepth
+// stops the tree that many levels below the roots. A group that was never published
+// has an empty tree.
+func (m *MenuService) FindPublishedMenu(ctx context.Context, groupID uuid.UUID, maxDepth *int, at *time.Time) ([]entity.MenuEntity, *time.Time, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] FindPublishedMenu - 1")
+		return nil, nil, err
+	}
+
+	menus, err := m.MenuRepoInterface.FindSubtree(ctx, entity.MenuQueryEntity{GroupID: groupID, MaxDepth: maxDepth, Published: true})
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindPublishedMenu - 2")
+		return nil, nil, err
+	}
+
+	if at == nil {
+		now := time.Now().UTC()
+		at = &now
+	}
+
+	return m.presentTree(ctx, menus, at, true)
+}
+
+// FindPublishedMenuByID implements MenuServiceInterface.
+// Only the published subtree of the menu is read, down to maxDepth levels below it
+// when set, and filtered like FindPublishedMenu at the server time. A menu hidden
+// through one of its ancestors is not found either.
+func (m *MenuService) FindPublishedMenuByID(ctx context.Context, id uuid.UUID, maxDepth *int) (*entity.MenuEntity, error) {
+	subtree, err := m.MenuRepoInterface.FindSubtree(ctx, entity.MenuQueryEntity{ID: &id, MaxDepth: maxDepth, Published: true})
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindPublishedMenuByID - 1")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	readable, err := m.readable(ctx, subtree, &now, m.MenuRepoInterface.FindPublishedMenusByIDs)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindPublishedMenuByID - 2")
+		return nil, err
+	}
+	subtree = slices.DeleteFunc(subtree, func(menu entity.MenuEntity) bool {
+		return !readable[menu.ID]
+	})
+
+	// rows come ordered by depth, so the menu itself is first
+	if len(subtree) == 0 || subtree[0].ID != id {
+		return nil, errors.New("menu not found")
+	}
+
+	if err := m.localize(ctx, subtree, true); err != nil {
+		log.Err(err).Msg("[SERVICE] FindPublishedMenuByID - 3")
+		return nil, err
+	}
+
+	menu := subtree[0]
+	menu.Children = treemenu.BuildTree(subtree[1:], &menu.ID)
+
+	return &menu, nil
+}
+
+// PublishMenu implements MenuServiceInterface.
+// The current draft replaces the published tree of the group in one step.
+func (m *MenuService) PublishMenu(ctx context.Context, groupID uuid.UUID) (*entity.MenuPublicationEntity, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] PublishMenu - 1")
+		return nil, err
+	}
+
+	publication := entity.MenuPublicationEntity{
+		GroupID:     groupID,
+		PublishedBy: actor.FromContext(ctx),
+		PublishedAt: time.Now(),
+	}
+
+	err := m.transaction(ctx, func(ctx context.Context) error {
+		if err := m.MenuPublicationRepoInterface.SavePublication(ctx, publication); err != nil {
+			log.Err(err).Msg("[SERVICE] PublishMenu - 2")
+			return err
+		}
+
+		return m.audit(ctx, entity.AuditPublish, groupID, entity.AuditTargetMenuGroup, groupID, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publication, nil
+}
+
+// DiscardDraft implements MenuServiceInterface.
+// Every unpublished edit of the group is thrown away by resetting the draft to the
+// published tree and its translations; the reset is recorded as a revision so it can be rolled back.
+func (m *MenuService) DiscardDraft(ctx context.Context, groupID uuid.UUID) error {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] DiscardDraft - 1")
+		return err
+	}
+
+	if _, err := m.MenuPublicationRepoInterface.FindPublication(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] DiscardDraft - 2")
+		return err
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		previous, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] DiscardDraft - 3")
+			return err
+		}
+
+		published, err := m.MenuRepoInterface.FindSubtree(ctx, entity.MenuQueryEntity{GroupID: groupID, Published: true})
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] DiscardDraft - 4")
+			return err
+		}
+
+		if err := m.applySnapshot(ctx, groupID, published); err != nil {
+			log.Err(err).Msg("[SERVICE] DiscardDraft - 5")
+			return err
+		}
+
+		if err := m.MenuTranslationRepoInterface.ResetTranslations(ctx, groupID); err != nil {
+			log.Err(err).Msg("[SERVICE] DiscardDraft - 6")
+			return err
+		}
+
+		return m.recordTreeRevision(ctx, groupID, entity.RevisionDiscardDraft, previous)
+	})
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/utils/actor"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultRevisionListLimit is the page size of the revision history.
+const defaultRevisionListLimit = 50
+
+// revisionSnapshotInterval is how many revisions of a group are recorded as changes
+// only before the next one takes a full snapshot again.
+const revisionSnapshotInterval = 100
+
+// recordRevision records a change made with ctx and audits the changed menu. menuID
+// names that menu and before its state ahead of the change; touched holds every menu
+// the change may have written. It must run in the transaction of the change.
+func (m *MenuService) recordRevision(ctx context.Context, groupID uuid.UUID, operation string, menuID *uuid.UUID, before *entity.MenuEntity, touched []uuid.UUID) error {
+	live, err := m.MenuRepoInterface.FindMenusByIDs(ctx, touched)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] recordRevision - 1")
+		return err
+	}
+
+	liveByID := make(map[uuid.UUID]*entity.MenuEntity, len(live))
+	for i := range live {
+		liveByID[live[i].ID] = &live[i]
+	}
+
+	// a touched menu that is no longer live went to the trash
+	changes := make([]entity.MenuRevisionChangeEntity, 0, len(touched))
+	seen := make(map[uuid.UUID]bool, len(touched))
+	for _, id := range touched {
+		if !seen[id] {
+			seen[id] = true
+			changes = append(changes, entity.MenuRevisionChangeEntity{ID: id, Menu: liveByID[id]})
+		}
+	}
+
+	var after *entity.MenuEntity
+	if menuID != nil {
+		after = liveByID[*menuID]
+	}
+
+	if err := m.saveRevision(ctx, groupID, operation, menuID, before, after, changes); err != nil {
+		return err
+	}
+
+	if menuID == nil {
+		return m.audit(ctx, operation, groupID, entity.AuditTargetMenuGroup, groupID, nil)
+	}
+
+	changedFields, err := diffMenu(before, after)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] recordRevision - 2")
+		return err
+	}
+
+	return m.audit(ctx, operation, groupID, entity.AuditTargetMenu, *menuID, changedFields)
+}
+
+// recordTreeRevision is recordRevision for a change spanning the whole group, with
+// previous being the live menus of the group ahead of it. Every menu whose version
+// moved is recorded and audited.
+func (m *MenuService) recordTreeRevision(ctx context.Context, groupID uuid.UUID, operation string, previous []entity.MenuEntity) error {
+	current, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] recordTreeRevision - 1")
+		return err
+	}
+
+	versions := make(map[uuid.UUID]int64, len(previous))
+	for _, menu := range previous {
+		versions[menu.ID] = menu.Version
+	}
+
+	changes := []entity.MenuRevisionChangeEntity{}
+	for i, menu := range current {
+		if version, ok := versions[menu.ID]; !ok || version != menu.Version {
+			changes = append(changes, entity.MenuRevisionChangeEntity{ID: menu.ID, Menu: &current[i]})
+		}
+		delete(versions, menu.ID)
+	}
+	for _, menu := range previous {
+		if _, gone := versions[menu.ID]; gone {
+			changes = append(changes, entity.MenuRevisionChangeEntity{ID: menu.ID})
+		}
+	}
+
+	if err := m.saveRevision(ctx, groupID, operation, nil, nil, nil, changes); err != nil {
+		return err
+	}
+
+	return m.auditMenus(ctx, operation, groupID, previous, current)
+}
+
+// saveRevision stores the revision of a change. Every revisionSnapshotInterval
+// revisions, and on the first one of a group, it also takes a snapshot of the group.
+func (m *MenuService) saveRevision(ctx context.Context, groupID uuid.UUID, operation string, menuID *uuid.UUID, before, after *entity.MenuEntity, changes []entity.MenuRevisionChangeEntity) error {
+	revision := entity.MenuRevisionEntity{
+		GroupID:   groupID,
+		MenuID:    menuID,
+		Author:    actor.FromContext(ctx),
+		Operation: operation,
+		Before:    before,
+		After:     after,
+		Changes:   changes,
+	}
+
+	since, found, err := m.MenuRevisionRepoInterface.CountSinceSnapshot(ctx, groupID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] saveRevision - 1")
+		return err
+	}
+
+	if !found || since+1 >= revisionSnapshotInterval {
+		snapshot, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] saveRevision - 2")
+			return err
+		}
+		if snapshot == nil {
+			snapshot = []entity.MenuEntity{}
+		}
+		revision.Snapshot = snapshot
+	}
+
+	if _, err := m.MenuRevisionRepoInterface.CreateRevision(ctx, revision); err != nil {
+		log.Err(err).Msg("[SERVICE] saveRevision - 3")
+		return err
+	}
+
+	return nil
+}
+
+// menusAt rebuilds the live menus of the group right after revision: the last
+// snapshot at or before it with the changes of every later revision replayed on top.
+// Parents come before their children.
+func (m *MenuService) menusAt(ctx context.Context, revision *entity.MenuRevisionEntity) ([]entity.MenuEntity, error) {
+	chain, err := m.MenuRevisionRepoInterface.FindRevisionChain(ctx, revision.GroupID, revision.ID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] menusAt - 1")
+		return nil, err
+	}
+	if len(chain) == 0 || chain[0].Snapshot == nil {
+		return nil, errors.New("revision not found")
+	}
+
+	byID := make(map[uuid.UUID]entity.MenuEntity, len(chain[0].Snapshot))
+	for _, menu := range chain[0].Snapshot {
+		byID[menu.ID] = menu
+	}
+
+	for _, later := range chain[1:] {
+		for _, change := range later.Changes {
+			if change.Menu == nil {
+				delete(byID, change.ID)
+			} else {
+				byID[change.ID] = *change.Menu
+			}
+		}
+	}
+
+	menus := make([]entity.MenuEntity, 0, len(byID))
+	for _, menu := range byID {
+		menus = append(menus, menu)
+	}
+	slices.SortFunc(menus, func(a, b entity.MenuEntity) int {
+		return cmp.Or(cmp.Compare(a.Depth, b.Depth), cmp.Compare(a.SortOrder, b.SortOrder), slices.Compare(a.ID[:], b.ID[:]))
+	})
+
+	return menus, nil
+}
+
+// FindAllRevision implements MenuServiceInterface.
+// It returns one page of the history, newest first, and the cursor of the next page.
+func (m *MenuService) FindAllRevision(ctx context.Context, groupID uuid.UUID, cursor string, limit int) ([]entity.MenuRevisionEntity, string, error) {
+	if _, err := m.MenuGroupRepoInterface.FindMenuGroupByID(ctx, groupID); err != nil {
+		log.Err(err).Msg("[SERVICE] FindAllRevision - 1")
+		return nil, "", err
+	}
+
+	var beforeID int64
+	if cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, "", errors.New("invalid cursor")
+		}
+		beforeID = id
+	}
+
+	if limit <= 0 {
+		limit = defaultRevisionListLimit
+	}
+
+	revisions, err := m.MenuRevisionRepoInterface.FindAllRevision(ctx, groupID, beforeID, limit+1)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindAllRevision - 2")
+		return nil, "", err
+	}
+
+	if len(revisions) <= limit {
+		return revisions, "", nil
+	}
+
+	revisions = revisions[:limit]
+	return revisions, strconv.FormatInt(revisions[limit-1].ID, 10), nil
+}
+
+// FindAllMenuAsOf implements MenuServiceInterface.
+// It rebuilds the tree of the group from revisionID, or when that is 0 from the
+// last revision recorded at or before at, and filters it like FindAllMenu.
+func (m *MenuService) FindAllMenuAsOf(ctx context.Context, groupID uuid.UUID, revisionID int64, at time.Time, maxDepth *int) ([]entity.MenuEntity, error) {
+	revision, err := m.findRevision(ctx, groupID, revisionID, at)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindAllMenuAsOf - 1")
+		return nil, err
+	}
+
+	menus, err := m.menusAt(ctx, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDepth != nil {
+		menus = slices.DeleteFunc(menus, func(menu entity.MenuEntity) bool {
+			return menu.Depth > *maxDepth
+		})
+	}
+
+	tree, _, err := m.presentTree(ctx, menus, nil, false)
+	return tree, err
+}
+
+func (m *MenuService) findRevision(ctx context.Context, groupID uuid.UUID, revisionID int64, at time.Time) (*entity.MenuRevisionEntity, error) {
+	if revisionID == 0 {
+		return m.MenuRevisionRepoInterface.FindRevisionAsOf(ctx, groupID, at)
+	}
+
+	revision, err := m.MenuRevisionRepoInterface.FindRevisionByID(ctx, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.GroupID != groupID {
+		return nil, errors.New("revision not found")
+	}
+
+	return revision, nil
+}
+
+// RollbackRevision implements MenuServiceInterface.
+// The group is put back exactly as the revision saw it, in one transaction. The
+// rollback is itself recorded as a revision.
+func (m *MenuService) RollbackRevision(ctx context.Context, revisionID int64) error {
+	revision, err := m.MenuRevisionRepoInterface.FindRevisionByID(ctx, revisionID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] RollbackRevision - 1")
+		return err
+	}
+
+	snapshot, err := m.menusAt(ctx, revision)
+	if err != nil {
+		return err
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		previous, err := m.MenuRepoInterface.FindAllMenu(ctx, revision.GroupID)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] RollbackRevision - 2")
+			return err
+		}
+
+		if err := m.applySnapshot(ctx, revision.GroupID, snapshot); err != nil {
+			log.Err(err).Msg("[SERVICE] RollbackRevision - 3")
+			return err
+		}
+
+		return m.recordTreeRevision(ctx, revision.GroupID, entity.RevisionRollback, previous)
+	})
+}
+
+// applySnapshot makes the live menus of the group match snapshot: menus missing from
+// it go to the trash and every menu in it is written back, reviving trashed or
+// purged ones. It must run inside a transaction.
+func (m *MenuService) applySnapshot(ctx context.Context, groupID uuid.UUID, snapshot []entity.MenuEntity) error {
+	// parents have to be live before their children are written back
+	snapshot = slices.Clone(snapshot)
+	slices.SortStableFunc(snapshot, func(a, b entity.MenuEntity) int {
+		return cmp.Compare(a.Depth, b.Depth)
+	})
+
+	kept := make(map[uuid.UUID]bool, len(snapshot))
+	for _, menu := range snapshot {
+		kept[menu.ID] = true
+	}
+
+	live, err := m.MenuRepoInterface.FindAllMenu(ctx, groupID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] applySnapshot - 1")
+		return err
+	}
+
+	liveByID := make(map[uuid.UUID]bool, len(live))
+	for _, menu := range live {
+		liveByID[menu.ID] = true
+	}
+
+	for _, menu := range live {
+		if kept[menu.ID] {
+			continue
+		}
+
+		// trashed together with a missing live parent
+		if menu.MenuID != nil && liveByID[*menu.MenuID] && !kept[*menu.MenuID] {
+			continue
+		}
+		if _, err := m.MenuRepoInterface.DeleteMenu(ctx, menu.ID); err != nil {
+			log.Err(err).Msg("[SERVICE] applySnapshot - 2")
+			return err
+		}
+	}
+
+	for _, menu := range snapshot {
+		if err := m.MenuRepoInterface.UpsertMenu(ctx, menu); err != nil {
+			log.Err(err).Msg("[SERVICE] applySnapshot - 3")
+			return err
+		}
+	}
+
+	return nil
+}
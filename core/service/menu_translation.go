@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/utils/locale"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// FindTranslations implements MenuServiceInterface.
+// A menu the caller may not see at the server time is not found. With published set
+// the translations of the last publish of the menu are returned, else the draft ones.
+func (m *MenuService) FindTranslations(ctx context.Context, menuID uuid.UUID, published bool) ([]entity.MenuTranslationEntity, error) {
+	menus, err := m.menusByIDs(published)(ctx, []uuid.UUID{menuID})
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindTranslations - 1")
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	readable, err := m.readable(ctx, menus, &now, m.menusByIDs(published))
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindTranslations - 2")
+		return nil, err
+	}
+	if !readable[menuID] {
+		return nil, errors.New("menu not found")
+	}
+
+	find := m.MenuTranslationRepoInterface.FindTranslations
+	if published {
+		find = m.MenuTranslationRepoInterface.FindPublishedTranslations
+	}
+
+	translations, err := find(ctx, []uuid.UUID{menuID})
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] FindTranslations - 3")
+		return nil, err
+	}
+
+	if translations == nil {
+		translations = []entity.MenuTranslationEntity{}
+	}
+
+	return translations, nil
+}
+
+// SaveTranslation implements MenuServiceInterface.
+// The locale is stored in its canonical form, so "en_us" and "en-US" are the same label.
+func (m *MenuService) SaveTranslation(ctx context.Context, req entity.MenuTranslationEntity) error {
+	req.Locale = locale.Normalize(req.Locale)
+	if req.Locale == "" {
+		return errors.New("invalid locale")
+	}
+
+	menu, err := m.MenuRepoInterface.FindMenuByID(ctx, req.MenuID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] SaveTranslation - 1")
+		return err
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		before, err := m.findTranslation(ctx, req.MenuID, req.Locale)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] SaveTranslation - 2")
+			return err
+		}
+
+		if err := m.MenuTranslationRepoInterface.SaveTranslation(ctx, req); err != nil {
+			log.Err(err).Msg("[SERVICE] SaveTranslation - 3")
+			return err
+		}
+
+		return m.auditTranslation(ctx, entity.AuditSaveTranslation, *menu, req.Locale, before, &req.Name)
+	})
+}
+
+// DeleteTranslation implements MenuServiceInterface.
+func (m *MenuService) DeleteTranslation(ctx context.Context, menuID uuid.UUID, tag string) error {
+	tag = locale.Normalize(tag)
+	if tag == "" {
+		return errors.New("invalid locale")
+	}
+
+	menu, err := m.MenuRepoInterface.FindMenuByID(ctx, menuID)
+	if err != nil {
+		log.Err(err).Msg("[SERVICE] DeleteTranslation - 1")
+		return err
+	}
+
+	return m.transaction(ctx, func(ctx context.Context) error {
+		before, err := m.findTranslation(ctx, menuID, tag)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] DeleteTranslation - 2")
+			return err
+		}
+
+		if err := m.MenuTranslationRepoInterface.DeleteTranslation(ctx, menuID, tag); err != nil {
+			log.Err(err).Msg("[SERVICE] DeleteTranslation - 3")
+			return err
+		}
+
+		return m.auditTranslation(ctx, entity.AuditDeleteTranslation, *menu, tag, before, nil)
+	})
+}
+
+// findTranslation returns the name of menuID in tag, nil when it has none.
+func (m *MenuService) findTranslation(ctx context.Context, menuID uuid.UUID, tag string) (*string, error) {
+	translations, err := m.MenuTranslationRepoInterface.FindTranslations(ctx, []uuid.UUID{menuID})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, translation := range translations {
+		if translation.Locale == tag {
+			return &translation.Name, nil
+		}
+	}
+	return nil, nil
+}
+
+// auditTranslation audits a change of the name of menu in tag, recorded as the field
+// translations.<tag>. A nil name stands for no translation.
+func (m *MenuService) auditTranslation(ctx context.Context, operation string, menu entity.MenuEntity, tag string, before, after *string) error {
+	change := entity.AuditChangeEntity{Field: "translations." + tag}
+
+	var err error
+	if before != nil {
+		if change.Before, err = json.Marshal(*before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if change.After, err = json.Marshal(*after); err != nil {
+			return err
+		}
+	}
+
+	var changes []entity.AuditChangeEntity
+	if !bytes.Equal(change.Before, change.After) {
+		changes = []entity.AuditChangeEntity{change}
+	}
+
+	return m.audit(ctx, operation, menu.GroupID, entity.AuditTargetMenu, menu.ID, changes)
+}
+
+// localize replaces the name of each menu with its translation in the first locale of
+// the chain that has one: the locales asked for on ctx, then the configured fallbacks.
+// Menus keep their own name, reported as the default locale, when none matches or when
+// the chain reaches the default locale first. Published menus take the translations
+// published with them, others the draft ones.
+func (m *MenuService) localize(ctx context.Context, menus []entity.MenuEntity, published bool) error {
+	defaultLocale := locale.Normalize(m.Config.DefaultLocale)
+	chain := locale.Chain(locale.FromContext(ctx), m.Config.FallbackLocales)
+
+	names := map[uuid.UUID]map[string]string{}
+	if len(chain) > 0 && len(menus) > 0 {
+		ids := make([]uuid.UUID, 0, len(menus))
+		for _, menu := range menus {
+			ids = append(ids, menu.ID)
+		}
+
+		find := m.MenuTranslationRepoInterface.FindTranslations
+		if published {
+			find = m.MenuTranslationRepoInterface.FindPublishedTranslations
+		}
+
+		translations, err := find(ctx, ids)
+		if err != nil {
+			log.Err(err).Msg("[SERVICE] localize - 1")
+			return err
+		}
+
+		for _, translation := range translations {
+			if names[translation.MenuID] == nil {
+				names[translation.MenuID] = map[string]string{}
+			}
+			names[translation.MenuID][translation.Locale] = translation.Name
+		}
+	}
+
+	for i := range menus {
+		menus[i].Locale = defaultLocale
+
+		for _, tag := range chain {
+			if tag == defaultLocale {
+				break
+			}
+			if name, ok := names[menus[i].ID][tag]; ok {
+				menus[i].Name = name
+				menus[i].Locale = tag
+				break
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,3 @@
+alter table menus drop column if exists group_id;
+
+drop table if exists menu_groups;
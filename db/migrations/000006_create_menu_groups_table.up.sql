@@ -0,0 +1,19 @@
+create table
+    menu_groups (
+        id uuid primary key default gen_random_uuid (),
+        name varchar(100) not null,
+        slug varchar(100) not null unique,
+        description text,
+        created_at timestamp not null default current_timestamp,
+        updated_at timestamp default current_timestamp
+    );
+
+insert into menu_groups (name, slug) values ('Default', 'default');
+
+alter table menus add column group_id uuid references menu_groups (id) on delete restrict;
+
+update menus set group_id = (select id from menu_groups where slug = 'default');
+
+alter table menus alter column group_id set not null;
+
+create index idx_menus_group_id on menus (group_id);
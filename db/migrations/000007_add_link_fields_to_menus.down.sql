@@ -0,0 +1,9 @@
+alter table menus drop constraint if exists chk_menus_type;
+
+alter table menus
+    drop column if exists type,
+    drop column if exists url,
+    drop column if exists route_name,
+    drop column if exists icon,
+    drop column if exists target,
+    drop column if exists rel;
@@ -0,0 +1,10 @@
+alter table menus
+    add column type varchar(20) not null default 'internal',
+    add column url text not null default '',
+    add column route_name varchar(100) not null default '',
+    add column icon varchar(100) not null default '',
+    add column target varchar(20) not null default '',
+    add column rel varchar(100) not null default '';
+
+alter table menus
+    add constraint chk_menus_type check (type in ('internal', 'external', 'header', 'separator'));
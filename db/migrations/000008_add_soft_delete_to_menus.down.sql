@@ -0,0 +1,10 @@
+delete from menus where deleted_at is not null;
+
+alter table menus drop constraint if exists menus_menu_id_fkey;
+
+alter table menus
+    add constraint menus_menu_id_fkey foreign key (menu_id) references menus (id) on delete cascade;
+
+drop index if exists idx_menus_deleted_at;
+
+alter table menus drop column if exists deleted_at;
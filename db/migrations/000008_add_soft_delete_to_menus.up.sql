@@ -0,0 +1,9 @@
+alter table menus add column deleted_at timestamp;
+
+create index idx_menus_deleted_at on menus (deleted_at);
+
+-- children must survive a purge of their parent so they can be restored to root
+alter table menus drop constraint if exists menus_menu_id_fkey;
+
+alter table menus
+    add constraint menus_menu_id_fkey foreign key (menu_id) references menus (id) on delete set null;
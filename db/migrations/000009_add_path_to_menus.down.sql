@@ -0,0 +1,5 @@
+drop index if exists idx_menus_path;
+
+alter table menus drop constraint if exists chk_menus_depth_path;
+
+alter table menus drop column if exists path;
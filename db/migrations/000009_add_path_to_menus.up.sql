@@ -0,0 +1,23 @@
+create extension if not exists ltree;
+
+alter table menus add column path ltree;
+
+-- every label is the menu id without dashes, so a path lists the ids from root to the menu
+with recursive tree as (
+    select id, text2ltree(replace(id::text, '-', '')) as path
+    from menus
+    where menu_id is null
+    union all
+    select m.id, t.path || text2ltree(replace(m.id::text, '-', ''))
+    from menus m
+    inner join tree t on m.menu_id = t.id
+)
+update menus set path = tree.path, depth = nlevel(tree.path) - 1
+from tree
+where menus.id = tree.id;
+
+alter table menus alter column path set not null;
+
+alter table menus add constraint chk_menus_depth_path check (depth = nlevel(path) - 1);
+
+create index idx_menus_path on menus using gist (path);
@@ -0,0 +1,3 @@
+drop index if exists idx_menus_name_trgm;
+
+drop function if exists immutable_unaccent(text);
@@ -0,0 +1,10 @@
+create extension if not exists pg_trgm;
+
+create extension if not exists unaccent;
+
+-- unaccent() is only stable, so an index needs this immutable wrapper with a fixed dictionary
+create or replace function immutable_unaccent(text) returns text
+    language sql immutable parallel safe strict
+as $$ select public.unaccent('public.unaccent'::regdictionary, $1) $$;
+
+create index idx_menus_name_trgm on menus using gin (immutable_unaccent(lower(name)) gin_trgm_ops);
@@ -0,0 +1,20 @@
+create table
+    menu_revisions (
+        id bigserial primary key,
+        group_id uuid not null references menu_groups (id) on delete cascade,
+        menu_id uuid,
+        author varchar(100) not null,
+        operation varchar(30) not null,
+        before jsonb,
+        after jsonb,
+        -- the menus the change touched, a null menu for those it removed
+        changes jsonb not null default '[]',
+        -- every live menu of the group right after the change, only taken now and then as a
+        -- starting point to replay the changes from for as_of reads and rollback
+        snapshot jsonb,
+        created_at timestamp not null default current_timestamp
+    );
+
+create index idx_menu_revisions_group_id_created_at on menu_revisions (group_id, created_at);
+
+create index idx_menu_revisions_group_id_snapshot on menu_revisions (group_id, id) where snapshot is not null;
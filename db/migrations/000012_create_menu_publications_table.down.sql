@@ -0,0 +1,3 @@
+drop table if exists published_menus;
+
+drop table if exists menu_publications;
@@ -0,0 +1,21 @@
+-- the menus table is the draft; a group's published tree is the copy of its menus taken
+-- by the last publish, kept in rows shaped like menus so published reads can load a
+-- subtree to a depth with child counts the same way draft reads do
+create table
+    menu_publications (
+        group_id uuid primary key references menu_groups (id) on delete cascade,
+        published_by varchar(100) not null,
+        published_at timestamp not null default current_timestamp
+    );
+
+create table published_menus (like menus including defaults including constraints including indexes);
+
+alter table published_menus
+    add constraint published_menus_group_id_fkey foreign key (group_id) references menu_groups (id) on delete cascade;
+
+-- everything was live before drafts existed, so the current menus start out published
+insert into menu_publications (group_id, published_by)
+select id, 'system' from menu_groups;
+
+insert into published_menus
+select * from menus where deleted_at is null;
@@ -0,0 +1,11 @@
+alter table menus drop constraint if exists chk_menus_visibility_window;
+
+alter table menus drop column if exists visible_until;
+
+alter table menus drop column if exists visible_from;
+
+alter table published_menus drop constraint if exists chk_published_menus_visibility_window;
+
+alter table published_menus drop column if exists visible_until;
+
+alter table published_menus drop column if exists visible_from;
@@ -0,0 +1,14 @@
+alter table menus add column visible_from timestamp;
+
+alter table menus add column visible_until timestamp;
+
+alter table menus
+    add constraint chk_menus_visibility_window check (visible_from is null or visible_until is null or visible_from < visible_until);
+
+-- the published copy keeps the columns of menus
+alter table published_menus add column visible_from timestamp;
+
+alter table published_menus add column visible_until timestamp;
+
+alter table published_menus
+    add constraint chk_published_menus_visibility_window check (visible_from is null or visible_until is null or visible_from < visible_until);
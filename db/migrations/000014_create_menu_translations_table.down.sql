@@ -0,0 +1,3 @@
+drop table if exists published_menu_translations;
+
+drop table if exists menu_translations;
@@ -0,0 +1,21 @@
+create table
+    menu_translations (
+        menu_id uuid not null references menus (id) on delete cascade,
+        locale varchar(35) not null,
+        name varchar(100) not null,
+        created_at timestamp not null default current_timestamp,
+        updated_at timestamp not null default current_timestamp,
+        primary key (menu_id, locale)
+    );
+
+-- names as of the last publish of their menus' group, read by the published tree; they
+-- go with their published menu when a publish replaces it
+create table
+    published_menu_translations (
+        menu_id uuid not null references published_menus (id) on delete cascade,
+        locale varchar(35) not null,
+        name varchar(100) not null,
+        created_at timestamp not null default current_timestamp,
+        updated_at timestamp not null default current_timestamp,
+        primary key (menu_id, locale)
+    );
@@ -0,0 +1,7 @@
+alter table menus drop column if exists required_permissions;
+
+alter table menus drop column if exists required_roles;
+
+alter table published_menus drop column if exists required_permissions;
+
+alter table published_menus drop column if exists required_roles;
@@ -0,0 +1,8 @@
+alter table menus add column required_roles jsonb not null default '[]'::jsonb;
+
+alter table menus add column required_permissions jsonb not null default '[]'::jsonb;
+
+-- the published copy keeps the columns of menus
+alter table published_menus add column required_roles jsonb not null default '[]'::jsonb;
+
+alter table published_menus add column required_permissions jsonb not null default '[]'::jsonb;
@@ -0,0 +1,14 @@
+create table
+    users (
+        id uuid primary key default gen_random_uuid (),
+        username varchar(100) not null unique,
+        password_hash varchar(100) not null,
+        roles jsonb not null default '[]'::jsonb,
+        permissions jsonb not null default '[]'::jsonb,
+        scopes jsonb not null default '[]'::jsonb,
+        -- consecutive failed logins, reset on success or when the account gets locked
+        failed_logins int not null default 0,
+        locked_until timestamp,
+        created_at timestamp not null default current_timestamp,
+        updated_at timestamp default current_timestamp
+    );
@@ -0,0 +1,9 @@
+-- tokens logged out or rotated before they expire; rows past expires_at can go
+create table
+    revoked_tokens (
+        jti varchar(64) primary key,
+        expires_at timestamp not null,
+        revoked_at timestamp not null default current_timestamp
+    );
+
+create index idx_revoked_tokens_expires_at on revoked_tokens (expires_at);
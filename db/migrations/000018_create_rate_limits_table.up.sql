@@ -0,0 +1,9 @@
+-- request counts of the current rate limit window per client, shared by all replicas
+create table
+    rate_limits (
+        key varchar(200) primary key,
+        hits int not null,
+        reset_at timestamp not null
+    );
+
+create index idx_rate_limits_reset_at on rate_limits (reset_at);
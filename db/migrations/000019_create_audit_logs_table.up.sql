@@ -0,0 +1,20 @@
+-- who changed which menu or group, from where, and what each field was before and after
+create table
+    audit_logs (
+        id bigserial primary key,
+        actor varchar(100) not null,
+        client_ip varchar(45) not null default '',
+        request_id varchar(128) not null default '',
+        operation varchar(30) not null,
+        group_id uuid not null,
+        target_type varchar(20) not null,
+        target_id uuid not null,
+        changes jsonb not null default '[]',
+        created_at timestamp not null default current_timestamp
+    );
+
+create index idx_audit_logs_target_id on audit_logs (target_id);
+
+create index idx_audit_logs_actor on audit_logs (actor);
+
+create index idx_audit_logs_created_at on audit_logs (created_at);
@@ -0,0 +1,3 @@
+alter table menus drop column if exists version;
+
+alter table published_menus drop column if exists version;
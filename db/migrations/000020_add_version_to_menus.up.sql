@@ -0,0 +1,5 @@
+-- bumped on every write to the row, for optimistic concurrency on updates
+alter table menus add column version bigint not null default 1;
+
+-- the published copy keeps the version each menu had when it was published
+alter table published_menus add column version bigint not null default 1;
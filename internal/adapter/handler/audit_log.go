@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Export formats of the audit log.
+const (
+	auditFormatCSV    = "csv"
+	auditFormatNDJSON = "ndjson"
+)
+
+// auditCSVHeader names the columns of the CSV export; changes holds the JSON diff.
+var auditCSVHeader = []string{"id", "created_at", "actor", "client_ip", "request_id", "operation", "group_id", "target_type", "target_id", "changes"}
+
+type AuditLogHandlerInterface interface {
+	FindAllAuditLog(c *fiber.Ctx) error
+}
+
+type AuditLogHandler struct {
+	AuditLogServiceInterface service.AuditLogServiceInterface
+}
+
+func NewAuditLogHandler(auditLogServiceInterface service.AuditLogServiceInterface) AuditLogHandlerInterface {
+	return &AuditLogHandler{
+		AuditLogServiceInterface: auditLogServiceInterface,
+	}
+}
+
+// FindAllAuditLog implements AuditLogHandlerInterface.
+// By default it returns one page as JSON; ?format=csv or ?format=ndjson downloads
+// every matching record instead.
+func (a *AuditLogHandler) FindAllAuditLog(c *fiber.Ctx) error {
+	var (
+		resp    = response.CursorResponse{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+		filter  = entity.AuditLogFilterEntity{Actor: c.Query("actor")}
+	)
+
+	if value := c.Query("menu_id"); value != "" {
+		menuID, err := uuid.Parse(value)
+		if err != nil {
+			log.Error().Err(err).Msg("[HANDLER] FindAllAuditLog - 1")
+			respErr.Message = "Invalid menu_id format"
+			respErr.Status = false
+			return c.Status(fiber.StatusBadRequest).JSON(respErr)
+		}
+		filter.MenuID = &menuID
+	}
+
+	var err error
+	if filter.From, err = parseQueryTime(c.Query("from")); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllAuditLog - 2")
+		respErr.Message = "Invalid from, expected an RFC3339 timestamp"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	if filter.To, err = parseQueryTime(c.Query("to")); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllAuditLog - 3")
+		respErr.Message = "Invalid to, expected an RFC3339 timestamp"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		respErr.Message = "to must be after from"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	switch format := c.Query("format"); format {
+	case "", "json":
+	case auditFormatCSV, auditFormatNDJSON:
+		return a.exportAuditLog(c, filter, format)
+	default:
+		respErr.Message = "Invalid format, expected json, csv or ndjson"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit < 0 || limit > 100 {
+		respErr.Message = "Invalid limit"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	auditLogs, nextCursor, err := a.AuditLogServiceInterface.FindAllAuditLog(ctx, filter, c.Query("cursor"), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllAuditLog - 4")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "invalid cursor" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find audit logs successfully"
+	resp.Status = true
+	resp.Data = auditLogs
+	resp.NextCursor = nextCursor
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// parseQueryTime parses an RFC3339 query value to UTC, nil when empty.
+func parseQueryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	parsed = parsed.UTC()
+	return &parsed, nil
+}
+
+// exportAuditLog streams every record matching filter as an attachment. The status is
+// sent before the first record is read, so a failure part way only cuts the body short.
+func (a *AuditLogHandler) exportAuditLog(c *fiber.Ctx, filter entity.AuditLogFilterEntity, format string) error {
+	ctx := c.UserContext()
+
+	c.Attachment("audit." + format)
+	if format == auditFormatCSV {
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	} else {
+		c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	}
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		var write func(entity.AuditLogEntity) error
+
+		if format == auditFormatCSV {
+			writer := csv.NewWriter(w)
+			if err := writer.Write(auditCSVHeader); err != nil {
+				log.Error().Err(err).Msg("[HANDLER] exportAuditLog - 1")
+				return
+			}
+
+			write = func(auditLog entity.AuditLogEntity) error {
+				changes, err := json.Marshal(auditLog.Changes)
+				if err != nil {
+					return err
+				}
+
+				return writer.Write([]string{
+					strconv.FormatInt(auditLog.ID, 10),
+					auditLog.CreatedAt.Format(time.RFC3339),
+					auditLog.Actor,
+					auditLog.ClientIP,
+					auditLog.RequestID,
+					auditLog.Operation,
+					auditLog.GroupID.String(),
+					auditLog.TargetType,
+					auditLog.TargetID.String(),
+					string(changes),
+				})
+			}
+			defer writer.Flush()
+		} else {
+			encoder := json.NewEncoder(w)
+			write = func(auditLog entity.AuditLogEntity) error {
+				return encoder.Encode(auditLog)
+			}
+		}
+
+		if err := a.AuditLogServiceInterface.ExportAuditLog(ctx, filter, write); err != nil {
+			log.Error().Err(err).Msg("[HANDLER] exportAuditLog - 2")
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler/request"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+type AuthHandlerInterface interface {
+	Login(c *fiber.Ctx) error
+	Refresh(c *fiber.Ctx) error
+	Logout(c *fiber.Ctx) error
+}
+
+type AuthHandler struct {
+	AuthServiceInterface service.AuthServiceInterface
+	Validator            *validator.Validate
+}
+
+func NewAuthHandler(authServiceInterface service.AuthServiceInterface, validator *validator.Validate) AuthHandlerInterface {
+	return &AuthHandler{
+		AuthServiceInterface: authServiceInterface,
+		Validator:            validator,
+	}
+}
+
+// Login implements AuthHandlerInterface.
+func (a *AuthHandler) Login(c *fiber.Ctx) error {
+	var (
+		req     = request.LoginRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Login - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := a.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Login - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	tokens, err := a.AuthServiceInterface.Login(ctx, req.Username, req.Password)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Login - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "invalid username or password" {
+			status = fiber.StatusUnauthorized
+		} else if err.Error() == "account is locked" {
+			status = fiber.StatusLocked
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Login successfully"
+	resp.Status = true
+	resp.Data = tokens
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Refresh implements AuthHandlerInterface.
+func (a *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var (
+		req     = request.RefreshTokenRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Refresh - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := a.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Refresh - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	tokens, err := a.AuthServiceInterface.Refresh(ctx, req.RefreshToken)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Refresh - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "invalid refresh token" {
+			status = fiber.StatusUnauthorized
+		} else if err.Error() == "account is locked" {
+			status = fiber.StatusLocked
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Refresh token successfully"
+	resp.Status = true
+	resp.Data = tokens
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// Logout implements AuthHandlerInterface.
+func (a *AuthHandler) Logout(c *fiber.Ctx) error {
+	var (
+		req     = request.RefreshTokenRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Logout - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := a.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Logout - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := a.AuthServiceInterface.Logout(ctx, req.RefreshToken); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] Logout - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "invalid refresh token" {
+			status = fiber.StatusUnauthorized
+		} else if err.Error() == "refresh token belongs to another user" {
+			status = fiber.StatusForbidden
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Logout successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"golang_menu_interview/core/domain/entity"
 	"golang_menu_interview/core/service"
 	"golang_menu_interview/internal/adapter/handler/request"
 	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/menucache"
 	"golang_menu_interview/utils/validation"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
@@ -17,21 +24,39 @@ type MenuHandlerInterface interface {
 	CreateMenu(c *fiber.Ctx) error
 	FindAllMenu(c *fiber.Ctx) error
 	FindMenuByID(c *fiber.Ctx) error
+	FindAncestors(c *fiber.Ctx) error
+	FindMenuList(c *fiber.Ctx) error
+	SearchMenu(c *fiber.Ctx) error
 	UpdateMenu(c *fiber.Ctx) error
 	DeleteMenu(c *fiber.Ctx) error
 	MoveMenu(c *fiber.Ctx) error
 	ReorderMenu(c *fiber.Ctx) error
+	FindTrashedMenu(c *fiber.Ctx) error
+	RestoreMenu(c *fiber.Ctx) error
+	ReplaceMenuTree(c *fiber.Ctx) error
+	ReorderChildren(c *fiber.Ctx) error
+	ReorderRoots(c *fiber.Ctx) error
+	FindAllRevision(c *fiber.Ctx) error
+	RollbackRevision(c *fiber.Ctx) error
+	PublishMenu(c *fiber.Ctx) error
+	DiscardDraft(c *fiber.Ctx) error
+	FindTranslations(c *fiber.Ctx) error
+	SaveTranslation(c *fiber.Ctx) error
+	DeleteTranslation(c *fiber.Ctx) error
+	FindCacheStats(c *fiber.Ctx) error
 }
 
 type MenuHandler struct {
 	MenuServiceInterface service.MenuServiceInterface
 	Validator            *validator.Validate
+	Cache                *menucache.Cache
 }
 
-func NewMenuHandler(menuServiceInterface service.MenuServiceInterface, validator *validator.Validate) MenuHandlerInterface {
+func NewMenuHandler(menuServiceInterface service.MenuServiceInterface, validator *validator.Validate, cache *menucache.Cache) MenuHandlerInterface {
 	return &MenuHandler{
 		MenuServiceInterface: menuServiceInterface,
 		Validator:            validator,
+		Cache:                cache,
 	}
 }
 
@@ -62,6 +87,15 @@ func (m *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 
 	var reqEntity entity.MenuEntity
 
+	groupUUID, err := uuid.Parse(req.GroupID)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] CreateMenu - invalid group_id")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	reqEntity.GroupID = groupUUID
+
 	if req.MenuID != "" {
 		menuUUID, err := uuid.Parse(req.MenuID)
 		if err != nil {
@@ -74,13 +108,36 @@ func (m *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 	}
 
 	reqEntity.Name = req.Name
+	reqEntity.Type = req.Type
+	reqEntity.URL = req.URL
+	reqEntity.RouteName = req.RouteName
+	reqEntity.Icon = req.Icon
+	reqEntity.Target = req.Target
+	reqEntity.Rel = req.Rel
 	reqEntity.SortOrder = req.SortOrder
+	reqEntity.VisibleFrom = parseOptionalTime(req.VisibleFrom)
+	reqEntity.VisibleUntil = parseOptionalTime(req.VisibleUntil)
+	reqEntity.RequiredRoles = req.RequiredRoles
+	reqEntity.RequiredPermissions = req.RequiredPermissions
 
 	if err := m.MenuServiceInterface.CreateMenu(ctx, reqEntity); err != nil {
 		log.Error().Err(err).Msg("[HANDLER] CreateCategory - 3")
+
+		var limitErr *service.LimitError
+		status := fiber.StatusInternalServerError
+		if errors.As(err, &limitErr) {
+			status = fiber.StatusUnprocessableEntity
+			respErr.Errors = limitErr
+		} else if err.Error() == "menu not found" || err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "parent menu belongs to a different group" || err.Error() == "separator menu cannot have children" ||
+			err.Error() == "visible_until must be after visible_from" {
+			status = fiber.StatusBadRequest
+		}
+
 		respErr.Message = err.Error()
 		respErr.Status = false
-		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+		return c.Status(status).JSON(respErr)
 	}
 
 	resp.Message = "Create menu successfully"
@@ -93,23 +150,115 @@ func (m *MenuHandler) CreateMenu(c *fiber.Ctx) error {
 // FindAllMenu implements MenuHandlerInterface.
 func (m *MenuHandler) FindAllMenu(c *fiber.Ctx) error {
 	var (
-		resp    = response.SuccessResponseDefault{}
+		resp    = response.MenuTreeResponse{}
 		respErr = response.ErrorResponseDefault{}
 		ctx     = c.UserContext()
 	)
 
-	menus, err := m.MenuServiceInterface.FindAllMenu(ctx)
+	groupID, err := uuid.Parse(c.Query("group_id"))
 	if err != nil {
 		log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	maxDepth, err := queryMaxDepth(c)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 2")
+		respErr.Message = "Invalid max_depth"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	// an admin previews the tree as seen by a caller holding only the given role
+	if asRole := c.Query("as_role"); asRole != "" {
+		if !claims.FromContext(ctx).HasRole(claims.RoleAdmin) {
+			respErr.Message = "Only admins can preview the tree as another role"
+			respErr.Status = false
+			return c.Status(fiber.StatusForbidden).JSON(respErr)
+		}
+		ctx = claims.NewContext(ctx, claims.Claims{Roles: []string{asRole}})
+	}
+
+	var at *time.Time
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 3")
+			respErr.Message = "Invalid at, expected an RFC3339 timestamp"
+			respErr.Status = false
+			return c.Status(fiber.StatusBadRequest).JSON(respErr)
+		}
+		parsed = parsed.UTC()
+		at = &parsed
+	}
+
+	key := treeCacheKey(ctx, c, groupID, maxDepth, at)
+	if entry, ok := m.Cache.Get(key); ok {
+		return sendTree(c, entry)
+	}
+	generation := m.Cache.Generation()
+
+	var (
+		menus        []entity.MenuEntity
+		nextChangeAt *time.Time
+	)
+	if asOf := c.Query("as_of"); asOf != "" {
+		revisionID, asOfTime, err := parseAsOf(asOf)
+		if err != nil {
+			log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 4")
+			respErr.Message = "Invalid as_of, expected a revision id or an RFC3339 timestamp"
+			respErr.Status = false
+			return c.Status(fiber.StatusBadRequest).JSON(respErr)
+		}
+
+		menus, err = m.MenuServiceInterface.FindAllMenuAsOf(ctx, groupID, revisionID, asOfTime, maxDepth)
+	} else {
+		switch c.Query("version", entity.MenuVersionPublished) {
+		case entity.MenuVersionPublished:
+			menus, nextChangeAt, err = m.MenuServiceInterface.FindPublishedMenu(ctx, groupID, maxDepth, at)
+		case entity.MenuVersionDraft:
+			menus, nextChangeAt, err = m.MenuServiceInterface.FindAllMenu(ctx, groupID, maxDepth, at)
+		default:
+			respErr.Message = "Invalid version, expected draft or published"
+			respErr.Status = false
+			return c.Status(fiber.StatusBadRequest).JSON(respErr)
+		}
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 5")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" || err.Error() == "revision not found" {
+			status = fiber.StatusNotFound
+		}
+
 		respErr.Message = err.Error()
 		respErr.Status = false
-		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+		return c.Status(status).JSON(respErr)
 	}
 
 	resp.Message = "Find all menus successfully"
 	resp.Status = true
 	resp.Data = menus
-	return c.Status(fiber.StatusOK).JSON(resp)
+	resp.NextChangeAt = nextChangeAt
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllMenu - 6")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+	}
+
+	// a tree read at the current time stops being valid when its next window opens
+	var expiresAt *time.Time
+	if at == nil {
+		expiresAt = nextChangeAt
+	}
+
+	return sendTree(c, m.Cache.Put(key, generation, body, expiresAt))
 }
 
 func (m *MenuHandler) FindMenuByID(c *fiber.Ctx) error {
@@ -129,17 +278,255 @@ func (m *MenuHandler) FindMenuByID(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(respErr)
 	}
 
-	menu, err := m.MenuServiceInterface.FindMenuByID(ctx, id)
+	maxDepth, err := queryMaxDepth(c)
 	if err != nil {
 		log.Error().Err(err).Msg("[HANDLER] FindMenuByID - 2")
+		respErr.Message = "Invalid max_depth"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	var menu *entity.MenuEntity
+	switch c.Query("version", entity.MenuVersionPublished) {
+	case entity.MenuVersionPublished:
+		menu, err = m.MenuServiceInterface.FindPublishedMenuByID(ctx, id, maxDepth)
+	case entity.MenuVersionDraft:
+		menu, err = m.MenuServiceInterface.FindMenuByID(ctx, id, maxDepth)
+	default:
+		respErr.Message = "Invalid version, expected draft or published"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuByID - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		}
+
 		respErr.Message = err.Error()
 		respErr.Status = false
-		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+		return c.Status(status).JSON(respErr)
 	}
 
 	resp.Message = "Find menu by id successfully"
 	resp.Status = true
 	resp.Data = menu
+	c.Set(fiber.HeaderETag, menuETag(menu.Version))
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// FindMenuList implements MenuHandlerInterface.
+func (m *MenuHandler) FindMenuList(c *fiber.Ctx) error {
+	var (
+		req     = request.MenuListRequest{}
+		resp    = response.CursorResponse{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.QueryParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuList - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuList - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	published, err := queryPublished(c)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuList - 3")
+		respErr.Message = "Invalid version, expected draft or published"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	reqEntity := entity.MenuListEntity{
+		GroupID: uuid.MustParse(req.GroupID),
+		Depth:   req.Depth,
+		Name:    req.Name,
+		Sort:    req.Sort,
+		Desc:    req.Order == "desc",
+		Limit:   req.Limit,
+		Cursor:    req.Cursor,
+		Published: published,
+	}
+
+	if req.ParentID != "" {
+		parentID := uuid.MustParse(req.ParentID)
+		reqEntity.ParentID = &parentID
+	}
+
+	if req.UpdatedSince != "" {
+		updatedSince, _ := time.Parse(time.RFC3339, req.UpdatedSince)
+		updatedSince = updatedSince.UTC()
+		reqEntity.UpdatedSince = &updatedSince
+	}
+
+	menus, nextCursor, err := m.MenuServiceInterface.FindMenuList(ctx, reqEntity)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuList - 4")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "invalid cursor" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find menu list successfully"
+	resp.Status = true
+	resp.Data = menus
+	resp.NextCursor = nextCursor
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// SearchMenu implements MenuHandlerInterface.
+func (m *MenuHandler) SearchMenu(c *fiber.Ctx) error {
+	var (
+		req     = request.SearchMenuRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.QueryParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SearchMenu - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	req.Q = strings.TrimSpace(req.Q)
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SearchMenu - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	published, err := queryPublished(c)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SearchMenu - 3")
+		respErr.Message = "Invalid version, expected draft or published"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	var result interface{}
+
+	groupID := uuid.MustParse(req.GroupID)
+	if req.Mode == "tree" {
+		result, err = m.MenuServiceInterface.SearchMenuTree(ctx, groupID, req.Q, req.Limit, published)
+	} else {
+		result, err = m.MenuServiceInterface.SearchMenu(ctx, groupID, req.Q, req.Limit, published)
+	}
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SearchMenu - 4")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Search menu successfully"
+	resp.Status = true
+	resp.Data = result
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// queryMaxDepth reads the optional ?max_depth= query, nil when it is absent.
+func queryMaxDepth(c *fiber.Ctx) (*int, error) {
+	value := c.Query("max_depth")
+	if value == "" {
+		return nil, nil
+	}
+
+	maxDepth, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	if maxDepth < 0 {
+		return nil, errors.New("max_depth must not be negative")
+	}
+
+	return &maxDepth, nil
+}
+
+// queryPublished reads the optional ?version= query, published when it is absent.
+func queryPublished(c *fiber.Ctx) (bool, error) {
+	switch c.Query("version", entity.MenuVersionPublished) {
+	case entity.MenuVersionPublished:
+		return true, nil
+	case entity.MenuVersionDraft:
+		return false, nil
+	default:
+		return false, errors.New("version must be draft or published")
+	}
+}
+
+// FindAncestors implements MenuHandlerInterface.
+func (m *MenuHandler) FindAncestors(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAncestors - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	published, err := queryPublished(c)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAncestors - 2")
+		respErr.Message = "Invalid version, expected draft or published"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	breadcrumbs, err := m.MenuServiceInterface.FindAncestors(ctx, id, c.QueryBool("include_siblings"), published)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAncestors - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find menu ancestors successfully"
+	resp.Status = true
+	resp.Data = breadcrumbs
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -147,7 +534,7 @@ func (m *MenuHandler) FindMenuByID(c *fiber.Ctx) error {
 func (m *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 
 	var (
-		req     = request.MenuRequest{}
+		req     = request.UpdateMenuRequest{}
 		resp    = response.SuccessResponseDefault{}
 		respErr = response.ErrorResponseDefault{}
 		ctx     = c.UserContext()
@@ -179,17 +566,69 @@ func (m *MenuHandler) UpdateMenu(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(respErr)
 	}
 
-	var reqEntity entity.MenuEntity
+	version, fromHeader, err := expectedVersion(c, req.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenu - 4")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	if version == 0 {
+		return versionRequired(c)
+	}
+
+	var reqEntity entity.UpdateMenuEntity
 
 	reqEntity.ID = id
+	reqEntity.Version = version
 	reqEntity.Name = req.Name
-	reqEntity.SortOrder = req.SortOrder
+	reqEntity.Type = req.Type
+	reqEntity.URL = req.URL
+	reqEntity.RouteName = req.RouteName
+	reqEntity.Icon = req.Icon
+	reqEntity.Target = req.Target
+	reqEntity.Rel = req.Rel
+
+	if req.SortOrder != nil {
+		reqEntity.SortOrder = *req.SortOrder
+		reqEntity.SortOrderSet = true
+	}
+	if req.VisibleFrom != nil {
+		reqEntity.VisibleFrom = parseOptionalTime(*req.VisibleFrom)
+		reqEntity.VisibleFromSet = true
+	}
+	if req.VisibleUntil != nil {
+		reqEntity.VisibleUntil = parseOptionalTime(*req.VisibleUntil)
+		reqEntity.VisibleUntilSet = true
+	}
+	if req.RequiredRoles != nil {
+		reqEntity.RequiredRoles = *req.RequiredRoles
+		reqEntity.RequiredRolesSet = true
+	}
+	if req.RequiredPermissions != nil {
+		reqEntity.RequiredPermissions = *req.RequiredPermissions
+		reqEntity.RequiredPermissionsSet = true
+	}
 
 	if err := m.MenuServiceInterface.UpdateMenu(ctx, reqEntity); err != nil {
-		log.Error().Err(err).Msg("[HANDLER] UpdateMenu - 4")
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenu - 5")
+
+		var conflictErr *service.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			return versionConflict(c, conflictErr, fromHeader)
+		}
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "menu with children cannot become a separator" || err.Error() == "visible_until must be after visible_from" ||
+			strings.Contains(err.Error(), " is required for an ") || strings.Contains(err.Error(), " allowed on a ") {
+			status = fiber.StatusBadRequest
+		}
+
 		respErr.Message = err.Error()
 		respErr.Status = false
-		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+		return c.Status(status).JSON(respErr)
 	}
 
 	resp.Message = "Update menu successfully"
@@ -217,16 +656,39 @@ func (m *MenuHandler) DeleteMenu(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(respErr)
 	}
 
-	if err := m.MenuServiceInterface.DeleteMenu(ctx, id); err != nil {
+	strategy := c.Query("strategy", entity.DeleteStrategyCascade)
+	if strategy != entity.DeleteStrategyCascade && strategy != entity.DeleteStrategyReparent && strategy != entity.DeleteStrategyReject {
+		respErr.Message = "Invalid strategy, must be one of cascade, reparent or reject"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	affected, err := m.MenuServiceInterface.DeleteMenu(ctx, id, strategy)
+	if err != nil {
 		log.Error().Err(err).Msg("[HANDLER] DeleteMenu - 2")
+
+		var limitErr *service.LimitError
+		status := fiber.StatusInternalServerError
+		if errors.As(err, &limitErr) {
+			status = fiber.StatusUnprocessableEntity
+			respErr.Errors = limitErr
+		} else if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "menu has children" {
+			status = fiber.StatusConflict
+		}
+
 		respErr.Message = err.Error()
 		respErr.Status = false
-		return c.Status(fiber.StatusNotFound).JSON(respErr)
+		return c.Status(status).JSON(respErr)
 	}
 
 	resp.Message = "Delete menu successfully"
 	resp.Status = true
-	resp.Data = nil
+	resp.Data = response.DeleteMenuResponse{
+		Strategy:            strategy,
+		AffectedDescendants: affected,
+	}
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
 
@@ -255,13 +717,35 @@ func (m *MenuHandler) MoveMenu(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
 	}
 
-	var reqEntity entity.MenuEntity
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] MoveMenu - 3")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	version, fromHeader, err := expectedVersion(c, req.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] MoveMenu - 4")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	if version == 0 {
+		return versionRequired(c)
+	}
+
+	var reqEntity entity.MoveMenuEntity
 	reqEntity.ID = id
+	reqEntity.Position = req.Position
+	reqEntity.Version = version
 
 	if req.NewMenuID != "" {
 		menuUUID, err := uuid.Parse(req.NewMenuID)
 		if err != nil {
-			log.Error().Err(err).Msg("[HANDLER] MoveMenu - 3")
+			log.Error().Err(err).Msg("[HANDLER] MoveMenu - 5")
 			respErr.Message = "Invalid new_menu_id format"
 			respErr.Status = false
 			return c.Status(fiber.StatusBadRequest).JSON(respErr)
@@ -271,13 +755,32 @@ func (m *MenuHandler) MoveMenu(c *fiber.Ctx) error {
 		reqEntity.MenuID = nil
 	}
 
+	if req.BeforeID != "" {
+		beforeID := uuid.MustParse(req.BeforeID)
+		reqEntity.BeforeID = &beforeID
+	}
+	if req.AfterID != "" {
+		afterID := uuid.MustParse(req.AfterID)
+		reqEntity.AfterID = &afterID
+	}
+
 	if err := m.MenuServiceInterface.MoveMenu(ctx, reqEntity); err != nil {
-		log.Error().Err(err).Msg("[HANDLER] MoveMenu - 4")
+		log.Error().Err(err).Msg("[HANDLER] MoveMenu - 6")
+
+		var conflictErr *service.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			return versionConflict(c, conflictErr, fromHeader)
+		}
 
+		var limitErr *service.LimitError
 		status := fiber.StatusInternalServerError
-		if err.Error() == "menu not found" {
+		if errors.As(err, &limitErr) {
+			status = fiber.StatusUnprocessableEntity
+			respErr.Errors = limitErr
+		} else if err.Error() == "menu not found" {
 			status = fiber.StatusNotFound
-		} else if err.Error() == "cannot move menu to its own descendant" {
+		} else if err.Error() == "cannot move menu to its own descendant" || err.Error() == "cannot move menu to a different group" ||
+			err.Error() == "separator menu cannot have children" || err.Error() == "anchor menu is not a child of the target parent" {
 			status = fiber.StatusBadRequest
 		}
 
@@ -310,15 +813,23 @@ func (m *MenuHandler) ReorderMenu(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(respErr)
 	}
 
-	if err := c.BodyParser(&req); err != nil {
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
 		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 2")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 3")
 		respErr.Message = err.Error()
 		respErr.Status = false
 		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
 	}
 
 	if err := m.Validator.Struct(&req); err != nil {
-		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 3")
+		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 4")
 		errors := validation.CustomValidator(err)
 		respErr.Message = "Invalid request"
 		respErr.Status = false
@@ -326,16 +837,35 @@ func (m *MenuHandler) ReorderMenu(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusBadRequest).JSON(respErr)
 	}
 
+	version, fromHeader, err := expectedVersion(c, req.Version)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 5")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+	if version == 0 {
+		return versionRequired(c)
+	}
+
 	var reqEntity entity.MenuEntity
 	reqEntity.ID = id
 	reqEntity.SortOrder = req.NewSortOrder
+	reqEntity.Version = version
 
-	if err := m.MenuServiceInterface.ReorderMenu(ctx, reqEntity); err != nil {
-		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 4")
+	if err := m.MenuServiceInterface.ReorderMenu(ctx, groupID, reqEntity); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReorderMenu - 6")
+
+		var conflictErr *service.VersionConflictError
+		if errors.As(err, &conflictErr) {
+			return versionConflict(c, conflictErr, fromHeader)
+		}
 
 		status := fiber.StatusInternalServerError
 		if err.Error() == "menu not found" {
 			status = fiber.StatusNotFound
+		} else if err.Error() == "menu does not belong to this group" {
+			status = fiber.StatusBadRequest
 		}
 
 		respErr.Message = err.Error()
@@ -348,3 +878,255 @@ func (m *MenuHandler) ReorderMenu(c *fiber.Ctx) error {
 	resp.Data = nil
 	return c.Status(fiber.StatusOK).JSON(resp)
 }
+
+// FindTrashedMenu implements MenuHandlerInterface.
+func (m *MenuHandler) FindTrashedMenu(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindTrashedMenu - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	menus, err := m.MenuServiceInterface.FindTrashedMenu(ctx, groupID)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindTrashedMenu - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find trashed menus successfully"
+	resp.Status = true
+	resp.Data = menus
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// RestoreMenu implements MenuHandlerInterface.
+func (m *MenuHandler) RestoreMenu(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] RestoreMenu - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.MenuServiceInterface.RestoreMenu(ctx, id); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] RestoreMenu - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found in trash" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Restore menu successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// ReplaceMenuTree implements MenuHandlerInterface.
+func (m *MenuHandler) ReplaceMenuTree(c *fiber.Ctx) error {
+	var (
+		req     = []request.MenuTreeRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReplaceMenuTree - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReplaceMenuTree - 2")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := m.Validator.Var(req, "dive"); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReplaceMenuTree - 3")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	diff, err := m.MenuServiceInterface.ReplaceMenuTree(ctx, groupID, toMenuTreeEntities(req))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReplaceMenuTree - 4")
+
+		var limitErr *service.LimitError
+		status := fiber.StatusInternalServerError
+		if errors.As(err, &limitErr) {
+			status = fiber.StatusUnprocessableEntity
+			respErr.Errors = limitErr
+		} else if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "separator menu cannot have children" || err.Error() == "visible_until must be after visible_from" ||
+			strings.HasSuffix(err.Error(), "does not belong to this group") ||
+			strings.HasSuffix(err.Error(), "appears more than once in the tree") {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Replace menu tree successfully"
+	resp.Status = true
+	resp.Data = diff
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// toMenuTreeEntities converts an already validated tree request, so ids parse cleanly.
+func toMenuTreeEntities(req []request.MenuTreeRequest) []entity.MenuEntity {
+	menus := make([]entity.MenuEntity, 0, len(req))
+	for _, node := range req {
+		menu := entity.MenuEntity{
+			Name:                node.Name,
+			Type:                node.Type,
+			URL:                 node.URL,
+			RouteName:           node.RouteName,
+			Icon:                node.Icon,
+			Target:              node.Target,
+			Rel:                 node.Rel,
+			VisibleFrom:         parseOptionalTime(node.VisibleFrom),
+			VisibleUntil:        parseOptionalTime(node.VisibleUntil),
+			RequiredRoles:       node.RequiredRoles,
+			RequiredPermissions: node.RequiredPermissions,
+			Children:            toMenuTreeEntities(node.Children),
+		}
+		if node.ID != "" {
+			menu.ID = uuid.MustParse(node.ID)
+		}
+		menus = append(menus, menu)
+	}
+	return menus
+}
+
+// parseOptionalTime converts an already validated RFC3339 value to UTC, nil when empty.
+func parseOptionalTime(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+	parsed = parsed.UTC()
+	return &parsed
+}
+
+// ReorderChildren implements MenuHandlerInterface.
+func (m *MenuHandler) ReorderChildren(c *fiber.Ctx) error {
+	respErr := response.ErrorResponseDefault{}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReorderChildren - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	return m.reorderSiblings(c, uuid.Nil, &id)
+}
+
+// ReorderRoots implements MenuHandlerInterface.
+func (m *MenuHandler) ReorderRoots(c *fiber.Ctx) error {
+	respErr := response.ErrorResponseDefault{}
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] ReorderRoots - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	return m.reorderSiblings(c, groupID, nil)
+}
+
+func (m *MenuHandler) reorderSiblings(c *fiber.Ctx, groupID uuid.UUID, parentID *uuid.UUID) error {
+	var (
+		req     = request.ReorderChildrenRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] reorderSiblings - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] reorderSiblings - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	ids := make([]uuid.UUID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		ids = append(ids, uuid.MustParse(id))
+	}
+
+	if err := m.MenuServiceInterface.ReorderChildren(ctx, groupID, parentID, ids); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] reorderSiblings - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" || err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "ids must list exactly the current children" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Reorder menus successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
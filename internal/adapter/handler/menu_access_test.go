@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/menucache"
+	"io"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// accessMenuRepo serves the menus of one group from memory, published and draft
+// alike, answering the reads the menu handlers make.
+type accessMenuRepo struct {
+	repository.MenuRepositoryInterface
+	menus   []entity.MenuEntity
+	trashed []entity.MenuEntity
+}
+
+func (r *accessMenuRepo) find(id uuid.UUID) (entity.MenuEntity, bool) {
+	for _, menu := range slices.Concat(r.menus, r.trashed) {
+		if menu.ID == id {
+			return menu, true
+		}
+	}
+	return entity.MenuEntity{}, false
+}
+
+func (r *accessMenuRepo) FindMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error) {
+	menu, ok := r.find(id)
+	if !ok {
+		return nil, errors.New("menu not found")
+	}
+	return &menu, nil
+}
+
+func (r *accessMenuRepo) FindSubtree(ctx context.Context, req entity.MenuQueryEntity) ([]entity.MenuEntity, error) {
+	if req.ID == nil {
+		return slices.Clone(r.menus), nil
+	}
+
+	target, ok := r.find(*req.ID)
+	if !ok {
+		return []entity.MenuEntity{}, nil
+	}
+
+	subtree := []entity.MenuEntity{}
+	for _, menu := range r.menus {
+		if menu.Path == target.Path || strings.HasPrefix(menu.Path, target.Path+".") {
+			subtree = append(subtree, menu)
+		}
+	}
+	return subtree, nil
+}
+
+func (r *accessMenuRepo) FindMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error) {
+	menus := []entity.MenuEntity{}
+	for _, menu := range r.menus {
+		if slices.Contains(ids, menu.ID) {
+			menus = append(menus, menu)
+		}
+	}
+	return menus, nil
+}
+
+func (r *accessMenuRepo) FindPublishedMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error) {
+	return r.FindMenusByIDs(ctx, ids)
+}
+
+func (r *accessMenuRepo) FindMenuList(ctx context.Context, req entity.MenuListEntity) ([]entity.MenuEntity, error) {
+	return slices.Clone(r.menus), nil
+}
+
+func (r *accessMenuRepo) SearchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, error) {
+	hits := []entity.MenuSearchHitEntity{}
+	for _, menu := range r.menus {
+		if strings.Contains(strings.ToLower(menu.Name), strings.ToLower(q)) {
+			hits = append(hits, entity.MenuSearchHitEntity{MenuEntity: menu})
+		}
+	}
+	return hits, nil
+}
+
+func (r *accessMenuRepo) FindAncestors(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error) {
+	target, ok := r.find(id)
+	if !ok {
+		return nil, errors.New("menu not found")
+	}
+
+	ancestors := []entity.MenuEntity{}
+	for _, menu := range r.menus {
+		if menu.Path == target.Path || strings.HasPrefix(target.Path, menu.Path+".") {
+			ancestors = append(ancestors, menu)
+		}
+	}
+	return ancestors, nil
+}
+
+func (r *accessMenuRepo) FindAncestorSiblings(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error) {
+	ancestors, err := r.FindAncestors(ctx, id, published)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := []entity.MenuEntity{}
+	for _, menu := range r.menus {
+		if slices.ContainsFunc(ancestors, func(ancestor entity.MenuEntity) bool {
+			return ancestor.Depth == menu.Depth && (ancestor.MenuID == nil) == (menu.MenuID == nil) &&
+				(ancestor.MenuID == nil || *ancestor.MenuID == *menu.MenuID)
+		}) {
+			siblings = append(siblings, menu)
+		}
+	}
+	return siblings, nil
+}
+
+func (r *accessMenuRepo) FindTrashedMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error) {
+	return slices.Clone(r.trashed), nil
+}
+
+type accessGroupRepo struct {
+	repository.MenuGroupRepositoryInterface
+}
+
+func (accessGroupRepo) FindMenuGroupByID(ctx context.Context, id uuid.UUID) (*entity.MenuGroupEntity, error) {
+	return &entity.MenuGroupEntity{ID: id}, nil
+}
+
+// accessRevisionRepo has a single revision, a snapshot of menus.
+type accessRevisionRepo struct {
+	repository.MenuRevisionRepositoryInterface
+	groupID uuid.UUID
+	menus   []entity.MenuEntity
+}
+
+func (r accessRevisionRepo) FindRevisionByID(ctx context.Context, id int64) (*entity.MenuRevisionEntity, error) {
+	return &entity.MenuRevisionEntity{ID: id, GroupID: r.groupID}, nil
+}
+
+func (r accessRevisionRepo) FindRevisionChain(ctx context.Context, groupID uuid.UUID, id int64) ([]entity.MenuRevisionEntity, error) {
+	return []entity.MenuRevisionEntity{{ID: id, GroupID: groupID, Snapshot: r.menus}}, nil
+}
+
+type accessTranslationRepo struct {
+	repository.MenuTranslationRepositoryInterface
+}
+
+func (accessTranslationRepo) FindTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error) {
+	return []entity.MenuTranslationEntity{}, nil
+}
+
+func (accessTranslationRepo) FindPublishedTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error) {
+	return []entity.MenuTranslationEntity{}, nil
+}
+
+// accessMenu places a menu under parent, nil for a root.
+func accessMenu(groupID uuid.UUID, parent *entity.MenuEntity, name string, roles ...string) entity.MenuEntity {
+	menu := entity.MenuEntity{ID: uuid.New(), GroupID: groupID, Name: name, Type: entity.MenuTypeInternal, RequiredRoles: roles}
+
+	label := strings.ReplaceAll(menu.ID.String(), "-", "")
+	menu.Path = label
+	if parent != nil {
+		menu.MenuID = &parent.ID
+		menu.Path = parent.Path + "." + label
+		menu.Depth = parent.Depth + 1
+	}
+	return menu
+}
+
+// TestRestrictedMenuHidden reads the tree through every read endpoint with a token
+// lacking the finance role and checks that Payroll, which requires it, and Payslips
+// below it never show up, while the other menus do.
+func TestRestrictedMenuHidden(t *testing.T) {
+	groupID := uuid.New()
+
+	home := accessMenu(groupID, nil, "Home")
+	news := accessMenu(groupID, &home, "News")
+	payroll := accessMenu(groupID, &home, "Payroll", "finance")
+	payslips := accessMenu(groupID, &payroll, "Payslips")
+	menus := []entity.MenuEntity{home, news, payroll, payslips}
+
+	deletedAt := time.Now()
+	oldNews := accessMenu(groupID, &home, "Old news")
+	oldPayroll := accessMenu(groupID, &home, "Old payroll", "finance")
+	oldNews.DeletedAt, oldPayroll.DeletedAt = &deletedAt, &deletedAt
+
+	menuService := service.NewMenuService(
+		&accessMenuRepo{menus: menus, trashed: []entity.MenuEntity{oldNews, oldPayroll}},
+		accessGroupRepo{},
+		accessRevisionRepo{groupID: groupID, menus: menus},
+		nil,
+		accessTranslationRepo{},
+		nil,
+		menucache.New(0),
+		config.Menu{},
+	)
+	menuHandler := NewMenuHandler(menuService, validator.New(), menucache.New(0))
+
+	var caller claims.Claims
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(claims.NewContext(c.UserContext(), caller))
+		return c.Next()
+	})
+	app.Get("/menus", menuHandler.FindAllMenu)
+	app.Get("/menus/trash", menuHandler.FindTrashedMenu)
+	app.Get("/menus/flat", menuHandler.FindMenuList)
+	app.Get("/menus/search", menuHandler.SearchMenu)
+	app.Get("/menus/:id", menuHandler.FindMenuByID)
+	app.Get("/menus/:id/ancestors", menuHandler.FindAncestors)
+	app.Get("/menus/:id/translations", menuHandler.FindTranslations)
+
+	group := "group_id=" + groupID.String()
+	tests := []struct {
+		name    string
+		target  string
+		status  int
+		shown   string
+		granted string
+	}{
+		{name: "published tree", target: "/menus?" + group, status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "draft tree", target: "/menus?version=draft&" + group, status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "tree as of a revision", target: "/menus?as_of=1&" + group, status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "published subtree", target: "/menus/" + home.ID.String(), status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "draft subtree", target: "/menus/" + home.ID.String() + "?version=draft", status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "restricted menu", target: "/menus/" + payroll.ID.String(), status: fiber.StatusNotFound, granted: "Payroll"},
+		{name: "restricted menu in the draft", target: "/menus/" + payroll.ID.String() + "?version=draft", status: fiber.StatusNotFound, granted: "Payroll"},
+		{name: "menu below a restricted one", target: "/menus/" + payslips.ID.String(), status: fiber.StatusNotFound, granted: "Payslips"},
+		{name: "flat listing", target: "/menus/flat?" + group, status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "draft flat listing", target: "/menus/flat?version=draft&" + group, status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "search", target: "/menus/search?q=pay&" + group, status: fiber.StatusOK, granted: "Payslips"},
+		{name: "draft search", target: "/menus/search?q=pay&version=draft&" + group, status: fiber.StatusOK, granted: "Payslips"},
+		{name: "search tree", target: "/menus/search?q=s&mode=tree&" + group, status: fiber.StatusOK, shown: "News", granted: "Payslips"},
+		{name: "breadcrumb with siblings", target: "/menus/" + news.ID.String() + "/ancestors?include_siblings=true", status: fiber.StatusOK, shown: "News", granted: "Payroll"},
+		{name: "breadcrumb of a restricted menu", target: "/menus/" + payslips.ID.String() + "/ancestors", status: fiber.StatusNotFound, granted: "Payslips"},
+		{name: "draft breadcrumb of a restricted menu", target: "/menus/" + payslips.ID.String() + "/ancestors?version=draft", status: fiber.StatusNotFound, granted: "Payslips"},
+		{name: "trash", target: "/menus/trash?" + group, status: fiber.StatusOK, shown: "Old news", granted: "Old payroll"},
+		{name: "translations of a restricted menu", target: "/menus/" + payroll.ID.String() + "/translations", status: fiber.StatusNotFound, granted: ""},
+		{name: "draft translations of a restricted menu", target: "/menus/" + payroll.ID.String() + "/translations?version=draft", status: fiber.StatusNotFound, granted: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller = claims.Claims{Subject: "reader", Roles: []string{"staff"}}
+			status, body := accessGet(t, app, tt.target)
+
+			if status != tt.status {
+				t.Fatalf("status = %d, want %d: %s", status, tt.status, body)
+			}
+			if strings.Contains(body, "Payroll") || strings.Contains(body, "Payslips") || strings.Contains(body, "Old payroll") {
+				t.Errorf("restricted menu in the response: %s", body)
+			}
+			if !strings.Contains(body, tt.shown) {
+				t.Errorf("%q missing from the response: %s", tt.shown, body)
+			}
+
+			// the finance role sees the menus the reader was refused
+			caller = claims.Claims{Subject: "accountant", Roles: []string{"finance"}}
+			if status, body := accessGet(t, app, tt.target); status != fiber.StatusOK || !strings.Contains(body, tt.granted) {
+				t.Errorf("with the finance role: status = %d, body %s", status, body)
+			}
+		})
+	}
+}
+
+func accessGet(t *testing.T, app *fiber.App, target string) (int, string) {
+	t.Helper()
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, target, nil))
+	if err != nil {
+		t.Fatalf("GET %s: %v", target, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("GET %s: %v", target, err)
+	}
+	return resp.StatusCode, string(body)
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/locale"
+	"golang_menu_interview/utils/menucache"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// FindCacheStats implements MenuHandlerInterface.
+func (m *MenuHandler) FindCacheStats(c *fiber.Ctx) error {
+	resp := response.SuccessResponseDefault{}
+	resp.Message = "Find menu cache stats successfully"
+	resp.Status = true
+	resp.Data = m.Cache.Stats()
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// treeCacheKey names a menu tree by everything that shapes it: the query, the
+// locales asked for and the roles and permissions on ctx it was filtered for.
+func treeCacheKey(ctx context.Context, c *fiber.Ctx, groupID uuid.UUID, maxDepth *int, at *time.Time) string {
+	depth := ""
+	if maxDepth != nil {
+		depth = strconv.Itoa(*maxDepth)
+	}
+
+	atKey := ""
+	if at != nil {
+		atKey = at.Format(time.RFC3339Nano)
+	}
+
+	// admins see every menu, whatever else their token holds
+	caller := claims.FromContext(ctx)
+	access := "admin"
+	if !caller.HasRole(claims.RoleAdmin) {
+		roles, permissions := slices.Clone(caller.Roles), slices.Clone(caller.Permissions)
+		slices.Sort(roles)
+		slices.Sort(permissions)
+		access = strings.Join(roles, ",") + ";" + strings.Join(permissions, ",")
+	}
+
+	return strings.Join([]string{
+		groupID.String(),
+		depth,
+		c.Query("version"),
+		c.Query("as_of"),
+		atKey,
+		strings.Join(locale.FromContext(ctx), ","),
+		access,
+	}, "|")
+}
+
+// sendTree answers with a serialized tree, or with 304 Not Modified when the caller
+// already holds it.
+func sendTree(c *fiber.Ctx, entry menucache.Entry) error {
+	c.Set(fiber.HeaderETag, entry.ETag)
+	c.Set(fiber.HeaderLastModified, entry.LastModified.Format(http.TimeFormat))
+	c.Vary(fiber.HeaderAuthorization)
+
+	if notModified(c, entry) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(entry.Body)
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since when the
+// caller sent no entity tags and the entry has none either. Dates only have second
+// precision, so a tree rebuilt within the second its previous version was sent
+// would look unchanged; an entity tag never does.
+func notModified(c *fiber.Ctx, entry menucache.Entry) bool {
+	if noneMatch := c.Get(fiber.HeaderIfNoneMatch); noneMatch != "" {
+		for _, tag := range strings.Split(noneMatch, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == entry.ETag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if modifiedSince := c.Get(fiber.HeaderIfModifiedSince); modifiedSince != "" && entry.ETag == "" {
+		since, err := http.ParseTime(modifiedSince)
+		return err == nil && !entry.LastModified.After(since)
+	}
+
+	return false
+}
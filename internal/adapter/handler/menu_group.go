@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler/request"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/validation"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type MenuGroupHandlerInterface interface {
+	CreateMenuGroup(c *fiber.Ctx) error
+	FindAllMenuGroup(c *fiber.Ctx) error
+	FindMenuGroupByID(c *fiber.Ctx) error
+	UpdateMenuGroup(c *fiber.Ctx) error
+	DeleteMenuGroup(c *fiber.Ctx) error
+}
+
+type MenuGroupHandler struct {
+	MenuGroupServiceInterface service.MenuGroupServiceInterface
+	Validator                 *validator.Validate
+}
+
+func NewMenuGroupHandler(menuGroupServiceInterface service.MenuGroupServiceInterface, validator *validator.Validate) MenuGroupHandlerInterface {
+	return &MenuGroupHandler{
+		MenuGroupServiceInterface: menuGroupServiceInterface,
+		Validator:                 validator,
+	}
+}
+
+// CreateMenuGroup implements MenuGroupHandlerInterface.
+func (m *MenuGroupHandler) CreateMenuGroup(c *fiber.Ctx) error {
+	var (
+		req     = request.MenuGroupRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] CreateMenuGroup - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] CreateMenuGroup - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	reqEntity := entity.MenuGroupEntity{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	}
+
+	if err := m.MenuGroupServiceInterface.CreateMenuGroup(ctx, reqEntity); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] CreateMenuGroup - 3")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+	}
+
+	resp.Message = "Create menu group successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusCreated).JSON(resp)
+}
+
+// FindAllMenuGroup implements MenuGroupHandlerInterface.
+func (m *MenuGroupHandler) FindAllMenuGroup(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	menuGroups, err := m.MenuGroupServiceInterface.FindAllMenuGroup(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllMenuGroup - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusInternalServerError).JSON(respErr)
+	}
+
+	resp.Message = "Find all menu groups successfully"
+	resp.Status = true
+	resp.Data = menuGroups
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// FindMenuGroupByID implements MenuGroupHandlerInterface.
+func (m *MenuGroupHandler) FindMenuGroupByID(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuGroupByID - 1")
+		respErr.Message = "Invalid menu group ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	menuGroup, err := m.MenuGroupServiceInterface.FindMenuGroupByID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindMenuGroupByID - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find menu group by id successfully"
+	resp.Status = true
+	resp.Data = menuGroup
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// UpdateMenuGroup implements MenuGroupHandlerInterface.
+func (m *MenuGroupHandler) UpdateMenuGroup(c *fiber.Ctx) error {
+	var (
+		req     = request.MenuGroupRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenuGroup - 1")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenuGroup - 2")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenuGroup - 3")
+		respErr.Message = "Invalid menu group ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	reqEntity := entity.MenuGroupEntity{
+		ID:          id,
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	}
+
+	if err := m.MenuGroupServiceInterface.UpdateMenuGroup(ctx, reqEntity); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] UpdateMenuGroup - 4")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Update menu group successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// DeleteMenuGroup implements MenuGroupHandlerInterface.
+func (m *MenuGroupHandler) DeleteMenuGroup(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DeleteMenuGroup - 1")
+		respErr.Message = "Invalid menu group ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.MenuGroupServiceInterface.DeleteMenuGroup(ctx, id); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DeleteMenuGroup - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "menu group still has menus" {
+			status = fiber.StatusConflict
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Delete menu group successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
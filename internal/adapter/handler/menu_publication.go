@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"golang_menu_interview/internal/adapter/handler/response"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// PublishMenu implements MenuHandlerInterface.
+func (m *MenuHandler) PublishMenu(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] PublishMenu - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	publication, err := m.MenuServiceInterface.PublishMenu(ctx, groupID)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] PublishMenu - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Publish menu successfully"
+	resp.Status = true
+	resp.Data = publication
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// DiscardDraft implements MenuHandlerInterface.
+func (m *MenuHandler) DiscardDraft(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DiscardDraft - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.MenuServiceInterface.DiscardDraft(ctx, groupID); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DiscardDraft - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "menu group has not been published" {
+			status = fiber.StatusConflict
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Discard menu draft successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"errors"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// parseAsOf reads an ?as_of= value: a revision id, or an RFC3339 timestamp when it is
+// not a number.
+func parseAsOf(value string) (int64, time.Time, error) {
+	if revisionID, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if revisionID <= 0 {
+			return 0, time.Time{}, errors.New("revision id must be positive")
+		}
+		return revisionID, time.Time{}, nil
+	}
+
+	at, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return 0, at.UTC(), nil
+}
+
+// FindAllRevision implements MenuHandlerInterface.
+func (m *MenuHandler) FindAllRevision(c *fiber.Ctx) error {
+	var (
+		resp    = response.CursorResponse{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	groupID, err := uuid.Parse(c.Query("group_id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllRevision - 1")
+		respErr.Message = "Invalid group_id format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	limit := c.QueryInt("limit")
+	if limit < 0 || limit > 100 {
+		respErr.Message = "Invalid limit"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	revisions, nextCursor, err := m.MenuServiceInterface.FindAllRevision(ctx, groupID, c.Query("cursor"), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindAllRevision - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu group not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "invalid cursor" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find menu revisions successfully"
+	resp.Status = true
+	resp.Data = revisions
+	resp.NextCursor = nextCursor
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// RollbackRevision implements MenuHandlerInterface.
+func (m *MenuHandler) RollbackRevision(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	revisionID, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil || revisionID <= 0 {
+		log.Error().Err(err).Msg("[HANDLER] RollbackRevision - 1")
+		respErr.Message = "Invalid revision ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.MenuServiceInterface.RollbackRevision(ctx, revisionID); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] RollbackRevision - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "revision not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Rollback menu revision successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/internal/adapter/handler/request"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// FindTranslations implements MenuHandlerInterface.
+func (m *MenuHandler) FindTranslations(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindTranslations - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	published, err := queryPublished(c)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindTranslations - 2")
+		respErr.Message = "Invalid version, expected draft or published"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	translations, err := m.MenuServiceInterface.FindTranslations(ctx, id, published)
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] FindTranslations - 3")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Find menu translations successfully"
+	resp.Status = true
+	resp.Data = translations
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// SaveTranslation implements MenuHandlerInterface.
+func (m *MenuHandler) SaveTranslation(c *fiber.Ctx) error {
+	var (
+		req     = request.MenuTranslationRequest{}
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SaveTranslation - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SaveTranslation - 2")
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(respErr)
+	}
+
+	if err := m.Validator.Struct(&req); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SaveTranslation - 3")
+		errors := validation.CustomValidator(err)
+		respErr.Message = "Invalid request"
+		respErr.Status = false
+		respErr.Errors = errors
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	reqEntity := entity.MenuTranslationEntity{
+		MenuID: id,
+		Locale: c.Params("locale"),
+		Name:   req.Name,
+	}
+
+	if err := m.MenuServiceInterface.SaveTranslation(ctx, reqEntity); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] SaveTranslation - 4")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "invalid locale" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Save menu translation successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
+
+// DeleteTranslation implements MenuHandlerInterface.
+func (m *MenuHandler) DeleteTranslation(c *fiber.Ctx) error {
+	var (
+		resp    = response.SuccessResponseDefault{}
+		respErr = response.ErrorResponseDefault{}
+		ctx     = c.UserContext()
+	)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DeleteTranslation - 1")
+		respErr.Message = "Invalid menu ID format"
+		respErr.Status = false
+		return c.Status(fiber.StatusBadRequest).JSON(respErr)
+	}
+
+	if err := m.MenuServiceInterface.DeleteTranslation(ctx, id, c.Params("locale")); err != nil {
+		log.Error().Err(err).Msg("[HANDLER] DeleteTranslation - 2")
+
+		status := fiber.StatusInternalServerError
+		if err.Error() == "translation not found" || err.Error() == "menu not found" {
+			status = fiber.StatusNotFound
+		} else if err.Error() == "invalid locale" {
+			status = fiber.StatusBadRequest
+		}
+
+		respErr.Message = err.Error()
+		respErr.Status = false
+		return c.Status(status).JSON(respErr)
+	}
+
+	resp.Message = "Delete menu translation successfully"
+	resp.Status = true
+	resp.Data = nil
+	return c.Status(fiber.StatusOK).JSON(resp)
+}
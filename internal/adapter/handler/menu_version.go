@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// menuETag is the strong entity tag of a menu at version.
+func menuETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// expectedVersion returns the version of the menu a write was made against: the
+// If-Match header when sent, else bodyVersion. fromHeader tells which one it was; a
+// zero version means the caller sent neither.
+func expectedVersion(c *fiber.Ctx, bodyVersion int64) (version int64, fromHeader bool, err error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return bodyVersion, false, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	if !ok {
+		return 0, true, errors.New("If-Match must be the ETag of the menu")
+	}
+
+	version, err = strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, true, errors.New("If-Match must be the ETag of the menu")
+	}
+
+	return version, true, nil
+}
+
+// versionRequired refuses a write that names no version of the menu.
+func versionRequired(c *fiber.Ctx) error {
+	respErr := response.ErrorResponseDefault{}
+	respErr.Message = "If-Match header or version field is required"
+	respErr.Status = false
+	return c.Status(fiber.StatusPreconditionRequired).JSON(respErr)
+}
+
+// versionConflict refuses a write made against a stale version with the current menu:
+// 412 when the version came from If-Match, 409 when it came from the body.
+func versionConflict(c *fiber.Ctx, conflictErr *service.VersionConflictError, fromHeader bool) error {
+	resp := response.VersionConflictResponse{}
+	resp.Message = conflictErr.Error()
+	resp.Status = false
+	resp.Data = conflictErr.Current
+
+	status := fiber.StatusConflict
+	if fromHeader {
+		status = fiber.StatusPreconditionFailed
+	}
+
+	c.Set(fiber.HeaderETag, menuETag(conflictErr.Current.Version))
+	return c.Status(status).JSON(resp)
+}
@@ -1,15 +1,100 @@
 package request
 
 type MenuRequest struct {
-	MenuID    string `json:"menu_id"`
-	Name      string `json:"name"  validate:"required"`
-	SortOrder int    `json:"sort_order"`
+	GroupID             string   `json:"group_id"`
+	MenuID              string   `json:"menu_id"`
+	Type                string   `json:"type" validate:"required,oneof=internal external header separator"`
+	Name                string   `json:"name" validate:"required_unless=Type separator,max=100"`
+	URL                 string   `json:"url" validate:"required_if=Type external,excluded_unless=Type external,omitempty,url"`
+	RouteName           string   `json:"route_name" validate:"required_if=Type internal,excluded_unless=Type internal,max=100"`
+	Icon                string   `json:"icon" validate:"excluded_if=Type separator,max=100"`
+	Target              string   `json:"target" validate:"excluded_if=Type header,excluded_if=Type separator,omitempty,oneof=_self _blank _parent _top"`
+	Rel                 string   `json:"rel" validate:"excluded_if=Type header,excluded_if=Type separator,max=100"`
+	SortOrder           int      `json:"sort_order"`
+	VisibleFrom         string   `json:"visible_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	VisibleUntil        string   `json:"visible_until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	RequiredRoles       []string `json:"required_roles" validate:"dive,required,max=100"`
+	RequiredPermissions []string `json:"required_permissions" validate:"dive,required,max=100"`
+	Version             int64    `json:"version" validate:"min=0"`
+}
+
+// UpdateMenuRequest is MenuRequest for updates, where type may be left out. The menu
+// then keeps its type and the link fields that are not sent keep their values, so
+// the rules of the type are checked by the service once they are known. Sort order,
+// schedule, roles and permissions that are not sent keep their values as well; an
+// empty visible_from or visible_until, or an empty list, clears them.
+type UpdateMenuRequest struct {
+	Type                string    `json:"type" validate:"omitempty,oneof=internal external header separator"`
+	Name                string    `json:"name" validate:"required_unless=Type separator,max=100"`
+	URL                 string    `json:"url" validate:"required_if=Type external,excluded_if=Type internal,excluded_if=Type header,excluded_if=Type separator,omitempty,url"`
+	RouteName           string    `json:"route_name" validate:"required_if=Type internal,excluded_if=Type external,excluded_if=Type header,excluded_if=Type separator,max=100"`
+	Icon                string    `json:"icon" validate:"excluded_if=Type separator,max=100"`
+	Target              string    `json:"target" validate:"excluded_if=Type header,excluded_if=Type separator,omitempty,oneof=_self _blank _parent _top"`
+	Rel                 string    `json:"rel" validate:"excluded_if=Type header,excluded_if=Type separator,max=100"`
+	SortOrder           *int      `json:"sort_order"`
+	VisibleFrom         *string   `json:"visible_from" validate:"omitempty,eq=|datetime=2006-01-02T15:04:05Z07:00"`
+	VisibleUntil        *string   `json:"visible_until" validate:"omitempty,eq=|datetime=2006-01-02T15:04:05Z07:00"`
+	RequiredRoles       *[]string `json:"required_roles" validate:"omitempty,dive,required,max=100"`
+	RequiredPermissions *[]string `json:"required_permissions" validate:"omitempty,dive,required,max=100"`
+	Version             int64     `json:"version" validate:"min=0"`
+}
+
+// MenuTreeRequest mirrors the nested shape returned by GET /menus. Items without
+// an id are created; the position inside Children decides the sort order.
+type MenuTreeRequest struct {
+	ID                  string            `json:"id" validate:"omitempty,uuid"`
+	Type                string            `json:"type" validate:"required,oneof=internal external header separator"`
+	Name                string            `json:"name" validate:"required_unless=Type separator,max=100"`
+	URL                 string            `json:"url" validate:"required_if=Type external,excluded_unless=Type external,omitempty,url"`
+	RouteName           string            `json:"route_name" validate:"required_if=Type internal,excluded_unless=Type internal,max=100"`
+	Icon                string            `json:"icon" validate:"excluded_if=Type separator,max=100"`
+	Target              string            `json:"target" validate:"excluded_if=Type header,excluded_if=Type separator,omitempty,oneof=_self _blank _parent _top"`
+	Rel                 string            `json:"rel" validate:"excluded_if=Type header,excluded_if=Type separator,max=100"`
+	VisibleFrom         string            `json:"visible_from" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	VisibleUntil        string            `json:"visible_until" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	RequiredRoles       []string          `json:"required_roles" validate:"dive,required,max=100"`
+	RequiredPermissions []string          `json:"required_permissions" validate:"dive,required,max=100"`
+	Children            []MenuTreeRequest `json:"children" validate:"dive"`
+}
+
+// MenuListRequest is the query string of the flat menu listing.
+type MenuListRequest struct {
+	GroupID      string `query:"group_id" validate:"required,uuid"`
+	ParentID     string `query:"parent_id" validate:"omitempty,uuid"`
+	Depth        *int   `query:"depth" validate:"omitempty,min=0"`
+	Name         string `query:"name" validate:"max=100"`
+	UpdatedSince string `query:"updated_since" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	Sort         string `query:"sort" validate:"omitempty,oneof=sort_order name depth updated_at"`
+	Order        string `query:"order" validate:"omitempty,oneof=asc desc"`
+	Cursor       string `query:"cursor"`
+	Limit        int    `query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+// SearchMenuRequest is the query string of the menu search.
+type SearchMenuRequest struct {
+	GroupID string `query:"group_id" validate:"required,uuid"`
+	Q       string `query:"q" validate:"required,max=100"`
+	Mode    string `query:"mode" validate:"omitempty,oneof=list tree"`
+	Limit   int    `query:"limit" validate:"omitempty,min=1,max=100"`
 }
 
 type MoveMenuRequest struct {
 	NewMenuID string `json:"new_menu_id"`
+	BeforeID  string `json:"before_id" validate:"omitempty,uuid,excluded_with=AfterID Position"`
+	AfterID   string `json:"after_id" validate:"omitempty,uuid,excluded_with=BeforeID Position"`
+	Position  *int   `json:"position" validate:"omitempty,min=0"`
+	Version   int64  `json:"version" validate:"min=0"`
 }
 
 type ReorderMenuRequest struct {
-	NewSortOrder int `json:"new_sort_order"`
+	NewSortOrder int   `json:"new_sort_order"`
+	Version      int64 `json:"version" validate:"min=0"`
+}
+
+type ReorderChildrenRequest struct {
+	IDs []string `json:"ids" validate:"required,dive,uuid"`
+}
+
+type MenuTranslationRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
 }
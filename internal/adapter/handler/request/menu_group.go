@@ -0,0 +1,7 @@
+package request
+
+type MenuGroupRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Slug        string `json:"slug" validate:"required"`
+	Description string `json:"description"`
+}
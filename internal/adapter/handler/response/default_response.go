@@ -11,6 +11,6 @@ type ErrorResponseDefault struct {
 }
 
 type SuccessResponseDefault struct {
-	Meta 
+	Meta
 	Data interface{} `json:"data,omitempty"`
 }
@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+// MenuTreeResponse is a menu tree plus the moment a visibility window next changes
+// it, so caches know how long the tree stays valid.
+type MenuTreeResponse struct {
+	Meta
+	Data         interface{} `json:"data"`
+	NextChangeAt *time.Time  `json:"next_change_at,omitempty"`
+}
+
+type DeleteMenuResponse struct {
+	Strategy            string `json:"strategy"`
+	AffectedDescendants int64  `json:"affected_descendants"`
+}
+
+// VersionConflictResponse refuses a write made against a stale version of a menu and
+// carries the menu as it stands now.
+type VersionConflictResponse struct {
+	Meta
+	Data interface{} `json:"data"`
+}
@@ -0,0 +1,9 @@
+package response
+
+// CursorResponse is a page of a keyset paginated listing. NextCursor is empty on the
+// last page.
+type CursorResponse struct {
+	Meta
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
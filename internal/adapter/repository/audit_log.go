@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepositoryInterface interface {
+	CreateAuditLog(ctx context.Context, req entity.AuditLogEntity) error
+	FindAllAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, beforeID int64, limit int) ([]entity.AuditLogEntity, error)
+}
+
+type AuditLogRepository struct {
+	DB *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepositoryInterface {
+	return &AuditLogRepository{
+		DB: db,
+	}
+}
+
+func (a *AuditLogRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, a.DB)
+}
+
+// CreateAuditLog implements AuditLogRepositoryInterface.
+func (a *AuditLogRepository) CreateAuditLog(ctx context.Context, req entity.AuditLogEntity) error {
+	changes := req.Changes
+	if changes == nil {
+		changes = []entity.AuditChangeEntity{}
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateAuditLog - 1")
+		return err
+	}
+
+	modelAuditLog := model.AuditLog{
+		Actor:      req.Actor,
+		ClientIP:   req.ClientIP,
+		RequestID:  req.RequestID,
+		Operation:  req.Operation,
+		GroupID:    req.GroupID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Changes:    data,
+	}
+
+	if err := a.db(ctx).Create(&modelAuditLog).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateAuditLog - 2")
+		return err
+	}
+
+	return nil
+}
+
+// FindAllAuditLog implements AuditLogRepositoryInterface.
+// Records come newest first; a beforeID above 0 continues after that record.
+func (a *AuditLogRepository) FindAllAuditLog(ctx context.Context, filter entity.AuditLogFilterEntity, beforeID int64, limit int) ([]entity.AuditLogEntity, error) {
+	modelAuditLogs := []model.AuditLog{}
+
+	query := a.db(ctx)
+	if filter.MenuID != nil {
+		query = query.Where("target_type = ? AND target_id = ?", entity.AuditTargetMenu, *filter.MenuID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&modelAuditLogs).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindAllAuditLog - 1")
+		return nil, err
+	}
+
+	auditLogEntities := []entity.AuditLogEntity{}
+	for _, data := range modelAuditLogs {
+		auditLogEntity := entity.AuditLogEntity{
+			ID:         data.ID,
+			Actor:      data.Actor,
+			ClientIP:   data.ClientIP,
+			RequestID:  data.RequestID,
+			Operation:  data.Operation,
+			GroupID:    data.GroupID,
+			TargetType: data.TargetType,
+			TargetID:   data.TargetID,
+			CreatedAt:  data.CreatedAt,
+		}
+		if err := json.Unmarshal(data.Changes, &auditLogEntity.Changes); err != nil {
+			log.Err(err).Msg("[REPOSITORY] FindAllAuditLog - 2")
+			return nil, err
+		}
+		auditLogEntities = append(auditLogEntities, auditLogEntity)
+	}
+
+	return auditLogEntities, nil
+}
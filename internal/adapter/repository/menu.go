@@ -2,26 +2,55 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"golang_menu_interview/core/domain/entity"
 	"golang_menu_interview/core/domain/model"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MenuRepositoryInterface interface {
 	CreateMenu(ctx context.Context, req entity.MenuEntity) error
-	FindAllMenu(ctx context.Context) ([]entity.MenuEntity, error)
+	FindAllMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error)
 	FindMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error)
+	LockMenu(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error)
 	UpdateMenu(ctx context.Context, req entity.MenuEntity) error
-	DeleteMenu(ctx context.Context, id uuid.UUID) error
+	DeleteMenu(ctx context.Context, id uuid.UUID) (int64, error)
 	MoveMenu(ctx context.Context, req entity.MenuEntity) error
-	ReorderMenu(ctx context.Context, req entity.MenuEntity) error
+	ReorderMenu(ctx context.Context, groupID uuid.UUID, req entity.MenuEntity) error
+	FindChildren(ctx context.Context, groupID uuid.UUID, parentID *uuid.UUID) ([]entity.MenuEntity, error)
+	UpdateSortOrders(ctx context.Context, ids []uuid.UUID) error
+	FindTrashedMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error)
+	FindTrashedMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error)
+	RestoreMenu(ctx context.Context, req entity.MenuEntity) error
+	PurgeMenu(ctx context.Context, before time.Time) ([]entity.MenuEntity, error)
+	CountChildren(ctx context.Context, id uuid.UUID) (int64, error)
+	CountDescendants(ctx context.Context, id uuid.UUID) (int64, error)
+	CountMenus(ctx context.Context, groupID uuid.UUID) (int64, error)
+	SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error)
+	ReparentChildren(ctx context.Context, id uuid.UUID, newParentID *uuid.UUID) (int64, error)
+	FindDescendants(ctx context.Context, id uuid.UUID) ([]entity.MenuEntity, error)
+	FindSubtree(ctx context.Context, req entity.MenuQueryEntity) ([]entity.MenuEntity, error)
+	FindSubtreeIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error)
+	FindMenuList(ctx context.Context, req entity.MenuListEntity) ([]entity.MenuEntity, error)
+	FindAncestors(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error)
+	FindMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error)
+	FindPublishedMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error)
+	SearchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, error)
+	FindAncestorSiblings(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error)
 	IsDescendant(ctx context.Context, targetID, menuID uuid.UUID) (bool, error)
-	UpdateDescendantsDepth(ctx context.Context, menuID uuid.UUID, depthDiff int) error
+	UpsertMenu(ctx context.Context, req entity.MenuEntity) error
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
+var menuColumns = []string{"id", "group_id", "menu_id", "name", "type", "url", "route_name", "icon", "target", "rel", "path", "depth", "sort_order", "visible_from", "visible_until", "required_roles", "required_permissions", "version", "updated_at", "deleted_at"}
+
 type MenuRepository struct {
 	DB *gorm.DB
 }
@@ -32,80 +61,186 @@ func NewMenuRepository(db *gorm.DB) MenuRepositoryInterface {
 	}
 }
 
+func (m *MenuRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, m.DB)
+}
+
+// PathIDs returns the ids of the menus on path, root first.
+func PathIDs(path string) []uuid.UUID {
+	labels := strings.Split(path, ".")
+
+	ids := make([]uuid.UUID, 0, len(labels))
+	for _, label := range labels {
+		if id, err := uuid.Parse(label); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
+
+// escapeLike escapes the LIKE wildcards in value so it only matches literally.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// menuTable is the table holding the published menus when published is set, else the
+// draft. Both have the columns of menus.
+func menuTable(published bool) string {
+	if published {
+		return "published_menus"
+	}
+	return "menus"
+}
+
+// pathLabel is the ltree label of a menu: its id without dashes.
+func pathLabel(id uuid.UUID) string {
+	return strings.ReplaceAll(id.String(), "-", "")
+}
+
+// childPath returns the path of id placed under parentID, or at the root when parentID is nil.
+func (m *MenuRepository) childPath(ctx context.Context, parentID *uuid.UUID, id uuid.UUID) (string, error) {
+	if parentID == nil {
+		return pathLabel(id), nil
+	}
+
+	parent := model.Menu{}
+	if err := m.db(ctx).Select("path").Where("id = ?", *parentID).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("menu not found")
+		}
+		return "", err
+	}
+
+	return parent.Path + "." + pathLabel(id), nil
+}
+
+// Transaction implements MenuRepositoryInterface.
+func (m *MenuRepository) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withTx(ctx, m.DB, fn)
+}
+
 // CreateMenu implements MenuRepositoryInterface.
 func (m *MenuRepository) CreateMenu(ctx context.Context, req entity.MenuEntity) error {
 
-	modelMenu := model.Menu{
-		MenuID:    req.MenuID,
-		Name:      req.Name,
-		Depth:     req.Depth,
-		SortOrder: req.SortOrder,
+	if req.ID == uuid.Nil {
+		req.ID = uuid.New()
 	}
 
-	if err := m.DB.Create(&modelMenu).Error; err != nil {
+	path, err := m.childPath(ctx, req.MenuID, req.ID)
+	if err != nil {
 		log.Err(err).Msg("[REPOSITORY] CreateMenu - 1 ")
 		return err
 	}
 
+	modelMenu := model.Menu{
+		ID:                  req.ID,
+		GroupID:             req.GroupID,
+		MenuID:              req.MenuID,
+		Name:                req.Name,
+		Type:                req.Type,
+		URL:                 req.URL,
+		RouteName:           req.RouteName,
+		Icon:                req.Icon,
+		Target:              req.Target,
+		Rel:                 req.Rel,
+		Path:                path,
+		Depth:               strings.Count(path, "."),
+		SortOrder:           req.SortOrder,
+		VisibleFrom:         req.VisibleFrom,
+		VisibleUntil:        req.VisibleUntil,
+		RequiredRoles:       nonNil(req.RequiredRoles),
+		RequiredPermissions: nonNil(req.RequiredPermissions),
+	}
+
+	if err := m.db(ctx).Create(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateMenu - 2 ")
+		return err
+	}
+
 	return nil
 
 }
 
 // FindAllMenu implements MenuRepositoryInterface.
-func (m *MenuRepository) FindAllMenu(ctx context.Context) ([]entity.MenuEntity, error) {
+func (m *MenuRepository) FindAllMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error) {
 	modelMenu := []model.Menu{}
 
-	if err := m.DB.Select("id", "menu_id", "name", "depth", "sort_order").Find(&modelMenu).Order("sort_order ASC").Error; err != nil {
+	if err := m.db(ctx).Select(menuColumns).Where("group_id = ?", groupID).Order("sort_order ASC").Find(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] FindAllMenu - 1")
 		return nil, err
 	}
 
 	var menuEntities []entity.MenuEntity
 	for _, data := range modelMenu {
-		menuEntities = append(menuEntities, entity.MenuEntity{
-			ID:        data.ID,
-			MenuID:    data.MenuID,
-			Name:      data.Name,
-			Depth:     data.Depth,
-			SortOrder: data.SortOrder,
-		})
+		menuEntities = append(menuEntities, toMenuEntity(data))
 	}
 
 	return menuEntities, nil
 }
 
-// FindHeroSectionByID implements MenuRepositoryInterface.
+// FindMenuByID implements MenuRepositoryInterface.
 func (m *MenuRepository) FindMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error) {
 	modelMenu := model.Menu{}
 
-	if err := m.DB.Select("id", "menu_id", "name", "depth", "sort_order").Where("id = ?", id).First(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Select(menuColumns).Where("id = ?", id).First(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] FindMenuByID - 1 ")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("menu not found")
+		}
 		return nil, err
 	}
 
-	return &entity.MenuEntity{
-		ID:        modelMenu.ID,
-		MenuID:    modelMenu.MenuID,
-		Name:      modelMenu.Name,
-		Depth:     modelMenu.Depth,
-		SortOrder: modelMenu.SortOrder,
-	}, nil
+	menuEntity := toMenuEntity(modelMenu)
+	return &menuEntity, nil
+
+}
+
+// LockMenu implements MenuRepositoryInterface.
+// The live menu is read and its row locked until the surrounding transaction ends, so
+// no other write can slip in between reading its version and writing it.
+func (m *MenuRepository) LockMenu(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error) {
+	modelMenu := model.Menu{}
+
+	if err := m.db(ctx).Select(menuColumns).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] LockMenu - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("menu not found")
+		}
+		return nil, err
+	}
 
+	menuEntity := toMenuEntity(modelMenu)
+	return &menuEntity, nil
 }
 
 // UpdateMenu implements MenuRepositoryInterface.
 func (m *MenuRepository) UpdateMenu(ctx context.Context, req entity.MenuEntity) error {
 	modelMenu := model.Menu{}
 
-	if err := m.DB.Where("id = ?", req.ID).First(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Where("id = ?", req.ID).First(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] UpdateMenu - 1 ")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu not found")
+		}
 		return err
 	}
 
 	modelMenu.Name = req.Name
+	modelMenu.Type = req.Type
+	modelMenu.URL = req.URL
+	modelMenu.RouteName = req.RouteName
+	modelMenu.Icon = req.Icon
+	modelMenu.Target = req.Target
+	modelMenu.Rel = req.Rel
 	modelMenu.SortOrder = req.SortOrder
+	modelMenu.VisibleFrom = req.VisibleFrom
+	modelMenu.VisibleUntil = req.VisibleUntil
+	modelMenu.RequiredRoles = nonNil(req.RequiredRoles)
+	modelMenu.RequiredPermissions = nonNil(req.RequiredPermissions)
+	modelMenu.Version++
 
-	if err := m.DB.Save(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Save(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] UpdateMenu - 2 ")
 		return err
 	}
@@ -113,55 +248,228 @@ func (m *MenuRepository) UpdateMenu(ctx context.Context, req entity.MenuEntity)
 	return nil
 }
 
-// DeleteCategory implements MenuRepositoryInterface.
-func (m *MenuRepository) DeleteMenu(ctx context.Context, id uuid.UUID) error {
+// DeleteMenu implements MenuRepositoryInterface.
+// The menu and its whole live subtree share one deleted_at so they can be restored together.
+// It returns how many menus were moved to the trash.
+func (m *MenuRepository) DeleteMenu(ctx context.Context, id uuid.UUID) (int64, error) {
 	modelMenu := model.Menu{}
 
-	if err := m.DB.Where("id = ?", id).First(&modelMenu).Error; err != nil {
-		log.Err(err).Msg("[REPOSITORY] DeleteCategory - 1 ")
+	if err := m.db(ctx).Where("id = ?", id).First(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] DeleteMenu - 1 ")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("menu not found")
+		}
+		return 0, err
+	}
+
+	query := `UPDATE menus SET deleted_at = $1, version = version + 1 WHERE path <@ $2::ltree AND deleted_at IS NULL`
+
+	result := m.db(ctx).Exec(query, time.Now(), modelMenu.Path)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("[REPOSITORY] DeleteMenu - 2 ")
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// FindTrashedMenu implements MenuRepositoryInterface.
+// Only the top of each deleted subtree is listed; its descendants come back with it on restore.
+func (m *MenuRepository) FindTrashedMenu(ctx context.Context, groupID uuid.UUID) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	query := `
+		SELECT m.* FROM menus m
+		LEFT JOIN menus p ON p.id = m.menu_id
+		WHERE m.group_id = $1
+			AND m.deleted_at IS NOT NULL
+			AND (p.id IS NULL OR p.deleted_at IS NULL OR p.deleted_at <> m.deleted_at)
+		ORDER BY m.deleted_at DESC
+	`
+
+	if err := m.db(ctx).Raw(query, groupID).Scan(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindTrashedMenu - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindTrashedMenuByID implements MenuRepositoryInterface.
+func (m *MenuRepository) FindTrashedMenuByID(ctx context.Context, id uuid.UUID) (*entity.MenuEntity, error) {
+	modelMenu := model.Menu{}
+
+	if err := m.db(ctx).Unscoped().Select(menuColumns).Where("id = ? AND deleted_at IS NOT NULL", id).First(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindTrashedMenuByID - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("menu not found in trash")
+		}
+		return nil, err
+	}
+
+	menuEntity := toMenuEntity(modelMenu)
+	return &menuEntity, nil
+}
+
+// RestoreMenu implements MenuRepositoryInterface.
+// Everything deleted together with the menu is brought back and the whole subtree is
+// reattached under req.MenuID (the root when nil).
+func (m *MenuRepository) RestoreMenu(ctx context.Context, req entity.MenuEntity) error {
+	trashed := model.Menu{}
+
+	if err := m.db(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", req.ID).First(&trashed).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] RestoreMenu - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu not found in trash")
+		}
 		return err
 	}
 
-	if err := m.DB.Delete(&modelMenu).Error; err != nil {
-		log.Err(err).Msg("[REPOSITORY] DeleteCategory - 2 ")
+	newPath, err := m.childPath(ctx, req.MenuID, req.ID)
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] RestoreMenu - 2")
 		return err
 	}
 
-	return nil
+	descendants := `
+		UPDATE menus SET
+			path = $1::ltree || subpath(path, nlevel($2::ltree)),
+			depth = nlevel($1::ltree) - 1 + nlevel(path) - nlevel($2::ltree),
+			deleted_at = CASE WHEN deleted_at = $3 THEN NULL ELSE deleted_at END,
+			version = version + 1
+		WHERE path <@ $2::ltree AND id <> $4
+	`
+
+	self := `UPDATE menus SET menu_id = $1, path = $2::ltree, depth = nlevel($2::ltree) - 1, deleted_at = NULL, version = version + 1, updated_at = now() WHERE id = $3`
+
+	return m.Transaction(ctx, func(ctx context.Context) error {
+		if err := m.db(ctx).Exec(descendants, newPath, trashed.Path, trashed.DeletedAt, req.ID).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] RestoreMenu - 3")
+			return err
+		}
+
+		if err := m.db(ctx).Exec(self, req.MenuID, newPath, req.ID).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] RestoreMenu - 4")
+			return err
+		}
+
+		return nil
+	})
+}
+
+// PurgeMenu implements MenuRepositoryInterface.
+// It returns the menus it deleted for good. Children left behind lose their parent
+// through the foreign key and become roots, so their subtrees are rebased onto them.
+func (m *MenuRepository) PurgeMenu(ctx context.Context, before time.Time) ([]entity.MenuEntity, error) {
+	modelMenus := []model.Menu{}
+
+	// an orphan is a root whose path still starts at its purged ancestors; each menu
+	// is rebased onto the nearest orphan above it
+	rebase := `
+		UPDATE menus d SET
+			path = subpath(d.path, nlevel(o.path) - 1),
+			depth = nlevel(d.path) - nlevel(o.path),
+			version = d.version + 1
+		FROM menus o
+		WHERE o.menu_id IS NULL AND nlevel(o.path) > 1 AND d.path <@ o.path
+		AND NOT EXISTS (
+			SELECT 1 FROM menus n
+			WHERE n.menu_id IS NULL AND n.path <@ o.path AND d.path <@ n.path AND nlevel(n.path) > nlevel(o.path)
+		)
+	`
+
+	err := m.Transaction(ctx, func(ctx context.Context) error {
+		if err := m.db(ctx).Unscoped().Clauses(clause.Returning{}).Where("deleted_at IS NOT NULL AND deleted_at < ?", before).Delete(&modelMenus).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] PurgeMenu - 1")
+			return err
+		}
+
+		if len(modelMenus) == 0 {
+			return nil
+		}
+
+		if err := m.db(ctx).Exec(rebase).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] PurgeMenu - 2")
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	menuEntities := make([]entity.MenuEntity, 0, len(modelMenus))
+	for _, data := range modelMenus {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
 }
 
 // MoveMenu implements MenuRepositoryInterface.
+// The paths and depths of the whole subtree are rewritten along with the menu itself.
 func (m *MenuRepository) MoveMenu(ctx context.Context, req entity.MenuEntity) error {
 	modelMenu := model.Menu{}
 
-	if err := m.DB.Where("id = ?", req.ID).First(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Where("id = ?", req.ID).First(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] MoveMenu - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu not found")
+		}
 		return err
 	}
 
-	modelMenu.MenuID = req.MenuID
-	modelMenu.Depth = req.Depth
-
-	if err := m.DB.Save(&modelMenu).Error; err != nil {
+	newPath, err := m.childPath(ctx, req.MenuID, req.ID)
+	if err != nil {
 		log.Err(err).Msg("[REPOSITORY] MoveMenu - 2")
 		return err
 	}
 
-	return nil
+	descendants := `
+		UPDATE menus SET
+			path = $1::ltree || subpath(path, nlevel($2::ltree)),
+			depth = nlevel($1::ltree) - 1 + nlevel(path) - nlevel($2::ltree),
+			version = version + 1
+		WHERE path <@ $2::ltree AND id <> $3
+	`
+
+	self := `UPDATE menus SET menu_id = $1, path = $2::ltree, depth = nlevel($2::ltree) - 1, version = version + 1, updated_at = now() WHERE id = $3`
+
+	return m.Transaction(ctx, func(ctx context.Context) error {
+		if err := m.db(ctx).Exec(descendants, newPath, modelMenu.Path, req.ID).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] MoveMenu - 3")
+			return err
+		}
+
+		if err := m.db(ctx).Exec(self, req.MenuID, newPath, req.ID).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] MoveMenu - 4")
+			return err
+		}
+
+		return nil
+	})
 }
 
 // ReorderMenu implements MenuRepositoryInterface.
-func (m *MenuRepository) ReorderMenu(ctx context.Context, req entity.MenuEntity) error {
+// Only a menu of groupID is reordered, any other id is not found.
+func (m *MenuRepository) ReorderMenu(ctx context.Context, groupID uuid.UUID, req entity.MenuEntity) error {
 	modelMenu := model.Menu{}
 
-	if err := m.DB.Where("id = ?", req.ID).First(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Where("id = ? AND group_id = ?", req.ID, groupID).First(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] ReorderMenu - 1")
 		return err
 	}
 
 	modelMenu.SortOrder = req.SortOrder
+	modelMenu.Version++
 
-	if err := m.DB.Save(&modelMenu).Error; err != nil {
+	if err := m.db(ctx).Save(&modelMenu).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] ReorderMenu - 2")
 		return err
 	}
@@ -169,20 +477,430 @@ func (m *MenuRepository) ReorderMenu(ctx context.Context, req entity.MenuEntity)
 	return nil
 }
 
+// CountChildren implements MenuRepositoryInterface.
+func (m *MenuRepository) CountChildren(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := m.db(ctx).Model(&model.Menu{}).Where("menu_id = ?", id).Count(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountChildren - 1")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountDescendants implements MenuRepositoryInterface.
+func (m *MenuRepository) CountDescendants(ctx context.Context, id uuid.UUID) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM menus d
+		INNER JOIN menus m ON d.path <@ m.path AND d.id <> m.id
+		WHERE m.id = $1 AND d.deleted_at IS NULL
+	`
+
+	var count int64
+	if err := m.db(ctx).Raw(query, id).Scan(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountDescendants - 1")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// CountMenus implements MenuRepositoryInterface.
+func (m *MenuRepository) CountMenus(ctx context.Context, groupID uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := m.db(ctx).Model(&model.Menu{}).Where("group_id = ?", groupID).Count(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountMenus - 1")
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SubtreeHeight implements MenuRepositoryInterface.
+// It returns how many levels lie below the menu, 0 for a leaf.
+func (m *MenuRepository) SubtreeHeight(ctx context.Context, id uuid.UUID) (int, error) {
+	query := `
+		SELECT COALESCE(MAX(d.depth), m.depth) - m.depth FROM menus m
+		LEFT JOIN menus d ON d.path <@ m.path AND d.deleted_at IS NULL
+		WHERE m.id = $1
+		GROUP BY m.depth
+	`
+
+	var height int
+	if err := m.db(ctx).Raw(query, id).Scan(&height).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SubtreeHeight - 1")
+		return 0, err
+	}
+
+	return height, nil
+}
+
+// ReparentChildren implements MenuRepositoryInterface.
+// The live children of id move to newParentID and every descendant moves up one level
+// with them. Children already in the trash stay under id and keep their subtree.
+// It returns how many children were moved.
+func (m *MenuRepository) ReparentChildren(ctx context.Context, id uuid.UUID, newParentID *uuid.UUID) (int64, error) {
+	modelMenu := model.Menu{}
+
+	if err := m.db(ctx).Where("id = ?", id).First(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] ReparentChildren - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, errors.New("menu not found")
+		}
+		return 0, err
+	}
+
+	// subpath(path, 0, nlevel - 1) is the parent prefix, empty for a root menu
+	descendants := `
+		UPDATE menus SET
+			path = subpath($1::ltree, 0, nlevel($1::ltree) - 1) || subpath(path, nlevel($1::ltree)),
+			depth = depth - 1,
+			version = version + 1
+		WHERE path <@ $1::ltree AND id <> $2
+			AND NOT EXISTS (
+				SELECT 1 FROM menus c
+				WHERE c.menu_id = $2 AND c.deleted_at IS NOT NULL AND menus.path <@ c.path
+			)
+	`
+
+	var moved int64
+	err := m.Transaction(ctx, func(ctx context.Context) error {
+		if err := m.db(ctx).Exec(descendants, modelMenu.Path, id).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] ReparentChildren - 2")
+			return err
+		}
+
+		result := m.db(ctx).Model(&model.Menu{}).Where("menu_id = ?", id).
+			Updates(map[string]any{"menu_id": newParentID, "version": gorm.Expr("version + 1")})
+		if result.Error != nil {
+			log.Err(result.Error).Msg("[REPOSITORY] ReparentChildren - 3")
+			return result.Error
+		}
+		moved = result.RowsAffected
+
+		return nil
+	})
+
+	return moved, err
+}
+
+// FindDescendants implements MenuRepositoryInterface.
+func (m *MenuRepository) FindDescendants(ctx context.Context, id uuid.UUID) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	subtree := m.db(ctx).Model(&model.Menu{}).Select("path").Where("id = ?", id)
+	if err := m.db(ctx).Select(menuColumns).Where("path <@ (?) AND id <> ?", subtree, id).Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindDescendants - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindSubtreeIDs implements MenuRepositoryInterface.
+// It returns the ids of the menu and of every menu below it, trashed ones included.
+func (m *MenuRepository) FindSubtreeIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	ids := []uuid.UUID{}
+
+	subtree := m.db(ctx).Unscoped().Model(&model.Menu{}).Select("path").Where("id = ?", id)
+	if err := m.db(ctx).Unscoped().Model(&model.Menu{}).Where("path <@ (?)", subtree).Pluck("id", &ids).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindSubtreeIDs - 1")
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// menuWithChildCount is a menu row plus the number of its live children.
+type menuWithChildCount struct {
+	model.Menu
+	ChildCount int
+}
+
+// FindSubtree implements MenuRepositoryInterface.
+// Only the rows inside the requested subtree and depth are read; each one carries
+// its child count so the levels below the cut can be loaded later.
+func (m *MenuRepository) FindSubtree(ctx context.Context, req entity.MenuQueryEntity) ([]entity.MenuEntity, error) {
+	rows := []menuWithChildCount{}
+
+	table := menuTable(req.Published)
+	rowsOf := func() *gorm.DB {
+		return m.db(ctx).Model(&model.Menu{}).Table(table)
+	}
+
+	childCount := "(SELECT COUNT(*) FROM " + table + " c WHERE c.menu_id = " + table + ".id AND c.deleted_at IS NULL) AS child_count"
+	query := rowsOf().Select(append(menuColumns, childCount))
+
+	if req.ID != nil {
+		subtree := rowsOf().Select("path").Where("id = ?", *req.ID)
+		query = query.Where("path <@ (?)", subtree)
+
+		if req.MaxDepth != nil {
+			maxLevel := rowsOf().Select("depth + ?", *req.MaxDepth).Where("id = ?", *req.ID)
+			query = query.Where("depth <= (?)", maxLevel)
+		}
+	} else {
+		query = query.Where("group_id = ?", req.GroupID)
+
+		if req.MaxDepth != nil {
+			query = query.Where("depth <= ?", *req.MaxDepth)
+		}
+	}
+
+	if err := query.Order("depth ASC").Order("sort_order ASC").Find(&rows).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindSubtree - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, row := range rows {
+		menuEntity := toMenuEntity(row.Menu)
+		menuEntity.ChildCount = row.ChildCount
+		menuEntity.HasChildren = row.ChildCount > 0
+		menuEntities = append(menuEntities, menuEntity)
+	}
+
+	return menuEntities, nil
+}
+
+// FindMenuList implements MenuRepositoryInterface.
+// Rows are ordered by req.Sort with the id as tie breaker, so the pair of the last
+// row is a stable keyset cursor for the next page.
+func (m *MenuRepository) FindMenuList(ctx context.Context, req entity.MenuListEntity) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	query := m.db(ctx).Model(&model.Menu{}).Table(menuTable(req.Published)).Select(menuColumns).Where("group_id = ?", req.GroupID)
+
+	if req.ParentID != nil {
+		query = query.Where("menu_id = ?", *req.ParentID)
+	}
+	if req.Depth != nil {
+		query = query.Where("depth = ?", *req.Depth)
+	}
+	if req.Name != "" {
+		query = query.Where("name ILIKE ?", "%"+escapeLike(req.Name)+"%")
+	}
+	if req.UpdatedSince != nil {
+		query = query.Where("updated_at >= ?", *req.UpdatedSince)
+	}
+
+	direction, compare := "ASC", ">"
+	if req.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	// req.Sort is one of the entity.MenuSort* columns, never raw client input
+	if req.After != nil {
+		query = query.Where("("+req.Sort+", id) "+compare+" (?, ?)", req.After.Value, req.After.ID)
+	}
+
+	if err := query.Order(req.Sort + " " + direction).Order("id " + direction).Limit(req.Limit).Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindMenuList - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindMenusByIDs implements MenuRepositoryInterface.
+func (m *MenuRepository) FindMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	if len(ids) == 0 {
+		return []entity.MenuEntity{}, nil
+	}
+
+	if err := m.db(ctx).Select(menuColumns).Where("id IN ?", ids).Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindMenusByIDs - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindPublishedMenusByIDs implements MenuRepositoryInterface.
+func (m *MenuRepository) FindPublishedMenusByIDs(ctx context.Context, ids []uuid.UUID) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	if len(ids) == 0 {
+		return []entity.MenuEntity{}, nil
+	}
+
+	if err := m.db(ctx).Table("published_menus").Select(menuColumns).Where("id IN ?", ids).Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindPublishedMenusByIDs - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// menuWithScore is a menu row plus how well it matched a search.
+type menuWithScore struct {
+	model.Menu
+	Score float64
+}
+
+// SearchMenu implements MenuRepositoryInterface.
+// Names and q are compared lower cased and without accents. A name containing q
+// matches outright, otherwise a trigram word similarity above pg_trgm's threshold
+// is enough; substring matches come first, then the best scores.
+func (m *MenuRepository) SearchMenu(ctx context.Context, groupID uuid.UUID, q string, limit int, published bool) ([]entity.MenuSearchHitEntity, error) {
+	rows := []menuWithScore{}
+
+	query := `
+		SELECT m.*, word_similarity(immutable_unaccent(lower($2)), immutable_unaccent(lower(m.name))) AS score
+		FROM ` + menuTable(published) + ` m
+		WHERE m.group_id = $1 AND m.deleted_at IS NULL
+			AND (immutable_unaccent(lower(m.name)) LIKE '%' || immutable_unaccent(lower($3)) || '%'
+				OR immutable_unaccent(lower($2)) <% immutable_unaccent(lower(m.name)))
+		ORDER BY immutable_unaccent(lower(m.name)) LIKE '%' || immutable_unaccent(lower($3)) || '%' DESC, score DESC, m.name ASC
+		LIMIT $4
+	`
+
+	if err := m.db(ctx).Raw(query, groupID, q, escapeLike(q), limit).Scan(&rows).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SearchMenu - 1")
+		return nil, err
+	}
+
+	hits := []entity.MenuSearchHitEntity{}
+	for _, row := range rows {
+		hits = append(hits, entity.MenuSearchHitEntity{MenuEntity: toMenuEntity(row.Menu), Score: row.Score})
+	}
+
+	return hits, nil
+}
+
+// FindAncestors implements MenuRepositoryInterface.
+// It returns the chain from the root down to the menu itself, ordered by depth.
+func (m *MenuRepository) FindAncestors(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	rowsOf := func() *gorm.DB {
+		return m.db(ctx).Model(&model.Menu{}).Table(menuTable(published))
+	}
+
+	target := rowsOf().Select("path").Where("id = ?", id)
+	if err := rowsOf().Select(menuColumns).Where("path @> (?)", target).Order("depth ASC").Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindAncestors - 1")
+		return nil, err
+	}
+
+	if len(modelMenu) == 0 {
+		return nil, errors.New("menu not found")
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindAncestorSiblings implements MenuRepositoryInterface.
+// It returns every menu sharing a parent with the menu or one of its ancestors, which
+// is each level of its breadcrumb with the alternatives at that level.
+func (m *MenuRepository) FindAncestorSiblings(ctx context.Context, id uuid.UUID, published bool) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	table := menuTable(published)
+	query := `
+		SELECT s.* FROM ` + table + ` s
+		INNER JOIN ` + table + ` a ON s.group_id = a.group_id AND s.menu_id IS NOT DISTINCT FROM a.menu_id
+		INNER JOIN ` + table + ` t ON a.path @> t.path
+		WHERE t.id = $1 AND a.deleted_at IS NULL AND s.deleted_at IS NULL
+		ORDER BY s.depth ASC, s.sort_order ASC, s.name ASC
+	`
+
+	if err := m.db(ctx).Raw(query, id).Scan(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindAncestorSiblings - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// FindChildren implements MenuRepositoryInterface.
+// A nil parentID returns the roots of the group.
+func (m *MenuRepository) FindChildren(ctx context.Context, groupID uuid.UUID, parentID *uuid.UUID) ([]entity.MenuEntity, error) {
+	modelMenu := []model.Menu{}
+
+	query := m.db(ctx).Select(menuColumns).Where("group_id = ?", groupID)
+	if parentID != nil {
+		query = query.Where("menu_id = ?", *parentID)
+	} else {
+		query = query.Where("menu_id IS NULL")
+	}
+
+	if err := query.Order("sort_order ASC").Order("name ASC").Find(&modelMenu).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindChildren - 1")
+		return nil, err
+	}
+
+	menuEntities := []entity.MenuEntity{}
+	for _, data := range modelMenu {
+		menuEntities = append(menuEntities, toMenuEntity(data))
+	}
+
+	return menuEntities, nil
+}
+
+// UpdateSortOrders implements MenuRepositoryInterface.
+// Each id gets its index in ids as sort_order, leaving no gaps or duplicates. Menus
+// already in place are left alone, so their version does not move.
+func (m *MenuRepository) UpdateSortOrders(ctx context.Context, ids []uuid.UUID) error {
+	return m.Transaction(ctx, func(ctx context.Context) error {
+		for i, id := range ids {
+			query := m.db(ctx).Model(&model.Menu{}).Where("id = ? AND sort_order <> ?", id, i)
+			if err := query.Updates(map[string]any{"sort_order": i, "version": gorm.Expr("version + 1")}).Error; err != nil {
+				log.Err(err).Msg("[REPOSITORY] UpdateSortOrders - 1")
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // IsDescendant implements MenuRepositoryInterface.
+// It reports whether targetID is menuID itself or lies anywhere below it.
 func (m *MenuRepository) IsDescendant(ctx context.Context, targetID, menuID uuid.UUID) (bool, error) {
 	query := `
-		WITH RECURSIVE descendants AS (
-			SELECT id, menu_id FROM menus WHERE id = $1
-			UNION ALL
-			SELECT m.id, m.menu_id FROM menus m
-			INNER JOIN descendants d ON m.menu_id = d.id
+		SELECT EXISTS(
+			SELECT 1 FROM menus t
+			INNER JOIN menus m ON t.path <@ m.path
+			WHERE t.id = $1 AND m.id = $2
 		)
-		SELECT EXISTS(SELECT 1 FROM descendants WHERE id = $2)
 	`
 
 	var exists bool
-	if err := m.DB.Raw(query, menuID, targetID).Scan(&exists).Error; err != nil {
+	if err := m.db(ctx).Raw(query, targetID, menuID).Scan(&exists).Error; err != nil {
 		log.Err(err).Msg("[REPOSITORY] IsDescendant - 1")
 		return false, err
 	}
@@ -190,22 +908,124 @@ func (m *MenuRepository) IsDescendant(ctx context.Context, targetID, menuID uuid
 	return exists, nil
 }
 
-// UpdateDescendantsDepth implements MenuRepositoryInterface.
-func (m *MenuRepository) UpdateDescendantsDepth(ctx context.Context, menuID uuid.UUID, depthDiff int) error {
+// UpsertMenu implements MenuRepositoryInterface.
+// The menu is written back exactly as given under req.MenuID, whether it is live,
+// in the trash or already purged. Its parent must be live, so callers place parents
+// before children. Rows below a menu that changes place, such as descendants left in
+// the trash, are rebased onto its new path along with it.
+func (m *MenuRepository) UpsertMenu(ctx context.Context, req entity.MenuEntity) error {
+	path, err := m.childPath(ctx, req.MenuID, req.ID)
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] UpsertMenu - 1")
+		return err
+	}
+
+	existing := model.Menu{}
+	err = m.db(ctx).Unscoped().Select("path").Where("id = ?", req.ID).First(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Err(err).Msg("[REPOSITORY] UpsertMenu - 2")
+		return err
+	}
+
+	descendants := `
+		UPDATE menus SET
+			path = $1::ltree || subpath(path, nlevel($2::ltree)),
+			depth = nlevel($1::ltree) - 1 + nlevel(path) - nlevel($2::ltree),
+			version = version + 1
+		WHERE path <@ $2::ltree AND id <> $3
+	`
+
 	query := `
-		WITH RECURSIVE descendants AS (
-			SELECT id, depth FROM menus WHERE menu_id = $1
-			UNION ALL
-			SELECT m.id, m.depth FROM menus m
-			INNER JOIN descendants d ON m.menu_id = d.id
-		)
-		UPDATE menus SET depth = depth + $2 WHERE id IN (SELECT id FROM descendants)
+		INSERT INTO menus (id, group_id, menu_id, name, type, url, route_name, icon, target, rel, path, depth, sort_order,
+			visible_from, visible_until, required_roles, required_permissions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11::ltree, nlevel($11::ltree) - 1, $12, $13, $14, $15::jsonb, $16::jsonb)
+		ON CONFLICT (id) DO UPDATE SET
+			group_id = EXCLUDED.group_id,
+			menu_id = EXCLUDED.menu_id,
+			name = EXCLUDED.name,
+			type = EXCLUDED.type,
+			url = EXCLUDED.url,
+			route_name = EXCLUDED.route_name,
+			icon = EXCLUDED.icon,
+			target = EXCLUDED.target,
+			rel = EXCLUDED.rel,
+			path = EXCLUDED.path,
+			depth = EXCLUDED.depth,
+			sort_order = EXCLUDED.sort_order,
+			visible_from = EXCLUDED.visible_from,
+			visible_until = EXCLUDED.visible_until,
+			required_roles = EXCLUDED.required_roles,
+			required_permissions = EXCLUDED.required_permissions,
+			deleted_at = NULL,
+			version = menus.version + 1,
+			updated_at = now()
 	`
 
-	if err := m.DB.Exec(query, menuID, depthDiff).Error; err != nil {
-		log.Err(err).Msg("[REPOSITORY] UpdateDescendantsDepth - 1")
+	roles, err := json.Marshal(nonNil(req.RequiredRoles))
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] UpsertMenu - 3")
 		return err
 	}
 
-	return nil
+	permissions, err := json.Marshal(nonNil(req.RequiredPermissions))
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] UpsertMenu - 4")
+		return err
+	}
+
+	return m.Transaction(ctx, func(ctx context.Context) error {
+		if existing.Path != "" && existing.Path != path {
+			if err := m.db(ctx).Exec(descendants, path, existing.Path, req.ID).Error; err != nil {
+				log.Err(err).Msg("[REPOSITORY] UpsertMenu - 5")
+				return err
+			}
+		}
+
+		err := m.db(ctx).Exec(query, req.ID, req.GroupID, req.MenuID, req.Name, req.Type, req.URL, req.RouteName,
+			req.Icon, req.Target, req.Rel, path, req.SortOrder, req.VisibleFrom, req.VisibleUntil, string(roles), string(permissions)).Error
+		if err != nil {
+			log.Err(err).Msg("[REPOSITORY] UpsertMenu - 6")
+			return err
+		}
+
+		return nil
+	})
+}
+
+func toMenuEntity(data model.Menu) entity.MenuEntity {
+	var deletedAt *time.Time
+	if data.DeletedAt.Valid {
+		deletedAt = &data.DeletedAt.Time
+	}
+
+	return entity.MenuEntity{
+		ID:                  data.ID,
+		GroupID:             data.GroupID,
+		MenuID:              data.MenuID,
+		Name:                data.Name,
+		Type:                data.Type,
+		URL:                 data.URL,
+		RouteName:           data.RouteName,
+		Icon:                data.Icon,
+		Target:              data.Target,
+		Rel:                 data.Rel,
+		Path:                data.Path,
+		Depth:               data.Depth,
+		SortOrder:           data.SortOrder,
+		VisibleFrom:         data.VisibleFrom,
+		VisibleUntil:        data.VisibleUntil,
+		RequiredRoles:       data.RequiredRoles,
+		RequiredPermissions: data.RequiredPermissions,
+		Version:             data.Version,
+		UpdatedAt:           data.UpdatedAt,
+		DeletedAt:           deletedAt,
+	}
+}
+
+// nonNil keeps empty requirement lists stored as [] rather than null.
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
 }
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MenuGroupRepositoryInterface interface {
+	CreateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error
+	FindAllMenuGroup(ctx context.Context) ([]entity.MenuGroupEntity, error)
+	FindMenuGroupByID(ctx context.Context, id uuid.UUID) (*entity.MenuGroupEntity, error)
+	LockMenuGroup(ctx context.Context, id uuid.UUID) error
+	UpdateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error
+	DeleteMenuGroup(ctx context.Context, id uuid.UUID) error
+	CountMenus(ctx context.Context, id uuid.UUID) (int64, error)
+}
+
+type MenuGroupRepository struct {
+	DB *gorm.DB
+}
+
+func NewMenuGroupRepository(db *gorm.DB) MenuGroupRepositoryInterface {
+	return &MenuGroupRepository{
+		DB: db,
+	}
+}
+
+func (m *MenuGroupRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, m.DB)
+}
+
+// CreateMenuGroup implements MenuGroupRepositoryInterface.
+func (m *MenuGroupRepository) CreateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error {
+	modelMenuGroup := model.MenuGroup{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+	}
+
+	if err := m.db(ctx).Create(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateMenuGroup - 1")
+		return err
+	}
+
+	return nil
+}
+
+// FindAllMenuGroup implements MenuGroupRepositoryInterface.
+func (m *MenuGroupRepository) FindAllMenuGroup(ctx context.Context) ([]entity.MenuGroupEntity, error) {
+	modelMenuGroups := []model.MenuGroup{}
+
+	if err := m.db(ctx).Order("name ASC").Find(&modelMenuGroups).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindAllMenuGroup - 1")
+		return nil, err
+	}
+
+	menuGroupEntities := []entity.MenuGroupEntity{}
+	for _, data := range modelMenuGroups {
+		menuGroupEntities = append(menuGroupEntities, entity.MenuGroupEntity{
+			ID:          data.ID,
+			Name:        data.Name,
+			Slug:        data.Slug,
+			Description: data.Description,
+		})
+	}
+
+	return menuGroupEntities, nil
+}
+
+// FindMenuGroupByID implements MenuGroupRepositoryInterface.
+func (m *MenuGroupRepository) FindMenuGroupByID(ctx context.Context, id uuid.UUID) (*entity.MenuGroupEntity, error) {
+	modelMenuGroup := model.MenuGroup{}
+
+	if err := m.db(ctx).Where("id = ?", id).First(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindMenuGroupByID - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("menu group not found")
+		}
+		return nil, err
+	}
+
+	return &entity.MenuGroupEntity{
+		ID:          modelMenuGroup.ID,
+		Name:        modelMenuGroup.Name,
+		Slug:        modelMenuGroup.Slug,
+		Description: modelMenuGroup.Description,
+	}, nil
+}
+
+// LockMenuGroup implements MenuGroupRepositoryInterface.
+// The group row is locked until the surrounding transaction ends, so writes that
+// count the menus of the group take turns instead of passing their checks together.
+func (m *MenuGroupRepository) LockMenuGroup(ctx context.Context, id uuid.UUID) error {
+	modelMenuGroup := model.MenuGroup{}
+
+	if err := m.db(ctx).Select("id").Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] LockMenuGroup - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu group not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// UpdateMenuGroup implements MenuGroupRepositoryInterface.
+func (m *MenuGroupRepository) UpdateMenuGroup(ctx context.Context, req entity.MenuGroupEntity) error {
+	modelMenuGroup := model.MenuGroup{}
+
+	if err := m.db(ctx).Where("id = ?", req.ID).First(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] UpdateMenuGroup - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu group not found")
+		}
+		return err
+	}
+
+	modelMenuGroup.Name = req.Name
+	modelMenuGroup.Slug = req.Slug
+	modelMenuGroup.Description = req.Description
+
+	if err := m.db(ctx).Save(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] UpdateMenuGroup - 2")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteMenuGroup implements MenuGroupRepositoryInterface.
+func (m *MenuGroupRepository) DeleteMenuGroup(ctx context.Context, id uuid.UUID) error {
+	modelMenuGroup := model.MenuGroup{}
+
+	if err := m.db(ctx).Where("id = ?", id).First(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] DeleteMenuGroup - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("menu group not found")
+		}
+		return err
+	}
+
+	if err := m.db(ctx).Delete(&modelMenuGroup).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] DeleteMenuGroup - 2")
+		return err
+	}
+
+	return nil
+}
+
+// CountMenus implements MenuGroupRepositoryInterface.
+// Trashed menus are counted too since they still reference the group.
+func (m *MenuGroupRepository) CountMenus(ctx context.Context, id uuid.UUID) (int64, error) {
+	var count int64
+
+	if err := m.db(ctx).Unscoped().Model(&model.Menu{}).Where("group_id = ?", id).Count(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountMenus - 1")
+		return 0, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MenuPublicationRepositoryInterface interface {
+	FindPublication(ctx context.Context, groupID uuid.UUID) (*entity.MenuPublicationEntity, error)
+	SavePublication(ctx context.Context, req entity.MenuPublicationEntity) error
+}
+
+type MenuPublicationRepository struct {
+	DB *gorm.DB
+}
+
+func NewMenuPublicationRepository(db *gorm.DB) MenuPublicationRepositoryInterface {
+	return &MenuPublicationRepository{
+		DB: db,
+	}
+}
+
+func (m *MenuPublicationRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, m.DB)
+}
+
+// FindPublication implements MenuPublicationRepositoryInterface.
+func (m *MenuPublicationRepository) FindPublication(ctx context.Context, groupID uuid.UUID) (*entity.MenuPublicationEntity, error) {
+	modelPublication := model.MenuPublication{}
+
+	if err := m.db(ctx).Where("group_id = ?", groupID).First(&modelPublication).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindPublication - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("menu group has not been published")
+		}
+		return nil, err
+	}
+
+	publicationEntity := entity.MenuPublicationEntity{
+		GroupID:     modelPublication.GroupID,
+		PublishedBy: modelPublication.PublishedBy,
+		PublishedAt: modelPublication.PublishedAt,
+	}
+
+	return &publicationEntity, nil
+}
+
+// SavePublication implements MenuPublicationRepositoryInterface.
+// A group has a single publication, replaced on every publish together with its
+// published menus, which become a copy of the live menus of the group, and their
+// translations. Callers run it
+// in a transaction so readers never see a half copied tree.
+func (m *MenuPublicationRepository) SavePublication(ctx context.Context, req entity.MenuPublicationEntity) error {
+	modelPublication := model.MenuPublication{
+		GroupID:     req.GroupID,
+		PublishedBy: req.PublishedBy,
+		PublishedAt: req.PublishedAt,
+	}
+
+	if err := m.db(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&modelPublication).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SavePublication - 1")
+		return err
+	}
+
+	copyMenus := `
+		INSERT INTO published_menus (id, group_id, menu_id, name, type, url, route_name, icon, target, rel, path, depth, sort_order,
+			visible_from, visible_until, required_roles, required_permissions, version, created_at, updated_at)
+		SELECT id, group_id, menu_id, name, type, url, route_name, icon, target, rel, path, depth, sort_order,
+			visible_from, visible_until, required_roles, required_permissions, version, created_at, updated_at
+		FROM menus
+		WHERE group_id = $1 AND deleted_at IS NULL
+	`
+
+	if err := m.db(ctx).Exec(`DELETE FROM published_menus WHERE group_id = $1`, req.GroupID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SavePublication - 2")
+		return err
+	}
+
+	if err := m.db(ctx).Exec(copyMenus, req.GroupID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SavePublication - 3")
+		return err
+	}
+
+	// the translations of the previous copy went with its menus
+	copyTranslations := `
+		INSERT INTO published_menu_translations (menu_id, locale, name, created_at, updated_at)
+		SELECT t.menu_id, t.locale, t.name, t.created_at, t.updated_at
+		FROM menu_translations t
+		JOIN published_menus p ON p.id = t.menu_id
+		WHERE p.group_id = $1
+	`
+
+	if err := m.db(ctx).Exec(copyTranslations, req.GroupID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] SavePublication - 4")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type MenuRevisionRepositoryInterface interface {
+	CreateRevision(ctx context.Context, req entity.MenuRevisionEntity) (int64, error)
+	FindAllRevision(ctx context.Context, groupID uuid.UUID, beforeID int64, limit int) ([]entity.MenuRevisionEntity, error)
+	FindRevisionByID(ctx context.Context, id int64) (*entity.MenuRevisionEntity, error)
+	FindRevisionAsOf(ctx context.Context, groupID uuid.UUID, at time.Time) (*entity.MenuRevisionEntity, error)
+	FindRevisionChain(ctx context.Context, groupID uuid.UUID, id int64) ([]entity.MenuRevisionEntity, error)
+	CountSinceSnapshot(ctx context.Context, groupID uuid.UUID) (int64, bool, error)
+}
+
+// revisionListColumns leaves out the changes and the snapshot, which are only needed
+// to rebuild a tree.
+var revisionListColumns = []string{"id", "group_id", "menu_id", "author", "operation", "before", "after", "created_at"}
+
+type MenuRevisionRepository struct {
+	DB *gorm.DB
+}
+
+func NewMenuRevisionRepository(db *gorm.DB) MenuRevisionRepositoryInterface {
+	return &MenuRevisionRepository{
+		DB: db,
+	}
+}
+
+func (m *MenuRevisionRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, m.DB)
+}
+
+// CreateRevision implements MenuRevisionRepositoryInterface.
+func (m *MenuRevisionRepository) CreateRevision(ctx context.Context, req entity.MenuRevisionEntity) (int64, error) {
+	modelRevision := model.MenuRevision{
+		GroupID:   req.GroupID,
+		MenuID:    req.MenuID,
+		Author:    req.Author,
+		Operation: req.Operation,
+	}
+
+	var err error
+	if modelRevision.Before, err = marshalMenu(req.Before); err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateRevision - 1")
+		return 0, err
+	}
+	if modelRevision.After, err = marshalMenu(req.After); err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateRevision - 2")
+		return 0, err
+	}
+	if modelRevision.Changes, err = json.Marshal(nonNilChanges(req.Changes)); err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateRevision - 3")
+		return 0, err
+	}
+	// a nil snapshot is stored as NULL, an empty group as []
+	if req.Snapshot != nil {
+		if modelRevision.Snapshot, err = json.Marshal(req.Snapshot); err != nil {
+			log.Err(err).Msg("[REPOSITORY] CreateRevision - 4")
+			return 0, err
+		}
+	}
+
+	if err := m.db(ctx).Create(&modelRevision).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateRevision - 5")
+		return 0, err
+	}
+
+	return modelRevision.ID, nil
+}
+
+// FindAllRevision implements MenuRevisionRepositoryInterface.
+// Revisions come newest first; a beforeID above 0 continues after that revision.
+func (m *MenuRevisionRepository) FindAllRevision(ctx context.Context, groupID uuid.UUID, beforeID int64, limit int) ([]entity.MenuRevisionEntity, error) {
+	modelRevisions := []model.MenuRevision{}
+
+	query := m.db(ctx).Select(revisionListColumns).Where("group_id = ?", groupID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&modelRevisions).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindAllRevision - 1")
+		return nil, err
+	}
+
+	revisionEntities := []entity.MenuRevisionEntity{}
+	for _, data := range modelRevisions {
+		revisionEntity, err := toMenuRevisionEntity(data)
+		if err != nil {
+			log.Err(err).Msg("[REPOSITORY] FindAllRevision - 2")
+			return nil, err
+		}
+		revisionEntities = append(revisionEntities, *revisionEntity)
+	}
+
+	return revisionEntities, nil
+}
+
+// FindRevisionByID implements MenuRevisionRepositoryInterface.
+func (m *MenuRevisionRepository) FindRevisionByID(ctx context.Context, id int64) (*entity.MenuRevisionEntity, error) {
+	modelRevision := model.MenuRevision{}
+
+	if err := m.db(ctx).Where("id = ?", id).First(&modelRevision).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindRevisionByID - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("revision not found")
+		}
+		return nil, err
+	}
+
+	return toMenuRevisionEntity(modelRevision)
+}
+
+// FindRevisionAsOf implements MenuRevisionRepositoryInterface.
+// It returns the last revision of the group recorded at or before at.
+func (m *MenuRevisionRepository) FindRevisionAsOf(ctx context.Context, groupID uuid.UUID, at time.Time) (*entity.MenuRevisionEntity, error) {
+	modelRevision := model.MenuRevision{}
+
+	if err := m.db(ctx).Where("group_id = ? AND created_at <= ?", groupID, at).Order("id DESC").First(&modelRevision).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindRevisionAsOf - 1")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("revision not found")
+		}
+		return nil, err
+	}
+
+	return toMenuRevisionEntity(modelRevision)
+}
+
+// FindRevisionChain implements MenuRevisionRepositoryInterface.
+// It returns the last revision of the group holding a snapshot at or before id and
+// every revision after it up to id, oldest first.
+func (m *MenuRevisionRepository) FindRevisionChain(ctx context.Context, groupID uuid.UUID, id int64) ([]entity.MenuRevisionEntity, error) {
+	modelRevisions := []model.MenuRevision{}
+
+	checkpoint := m.db(ctx).Model(&model.MenuRevision{}).Select("max(id)").Where("group_id = ? AND id <= ? AND snapshot IS NOT NULL", groupID, id)
+	if err := m.db(ctx).Where("group_id = ? AND id <= ? AND id >= (?)", groupID, id, checkpoint).Order("id ASC").Find(&modelRevisions).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindRevisionChain - 1")
+		return nil, err
+	}
+
+	revisionEntities := []entity.MenuRevisionEntity{}
+	for _, data := range modelRevisions {
+		revisionEntity, err := toMenuRevisionEntity(data)
+		if err != nil {
+			log.Err(err).Msg("[REPOSITORY] FindRevisionChain - 2")
+			return nil, err
+		}
+		revisionEntities = append(revisionEntities, *revisionEntity)
+	}
+
+	return revisionEntities, nil
+}
+
+// CountSinceSnapshot implements MenuRevisionRepositoryInterface.
+// It counts the revisions of the group recorded after its last snapshot; found is
+// false when the group has no snapshot yet.
+func (m *MenuRevisionRepository) CountSinceSnapshot(ctx context.Context, groupID uuid.UUID) (int64, bool, error) {
+	var lastID *int64
+
+	if err := m.db(ctx).Model(&model.MenuRevision{}).Select("max(id)").Where("group_id = ? AND snapshot IS NOT NULL", groupID).Scan(&lastID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountSinceSnapshot - 1")
+		return 0, false, err
+	}
+	if lastID == nil {
+		return 0, false, nil
+	}
+
+	var count int64
+	if err := m.db(ctx).Model(&model.MenuRevision{}).Where("group_id = ? AND id > ?", groupID, *lastID).Count(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CountSinceSnapshot - 2")
+		return 0, false, err
+	}
+
+	return count, true, nil
+}
+
+func nonNilChanges(changes []entity.MenuRevisionChangeEntity) []entity.MenuRevisionChangeEntity {
+	if changes == nil {
+		return []entity.MenuRevisionChangeEntity{}
+	}
+	return changes
+}
+
+func marshalMenu(menu *entity.MenuEntity) ([]byte, error) {
+	if menu == nil {
+		return nil, nil
+	}
+	return json.Marshal(menu)
+}
+
+func unmarshalMenu(data []byte) (*entity.MenuEntity, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	menu := entity.MenuEntity{}
+	if err := json.Unmarshal(data, &menu); err != nil {
+		return nil, err
+	}
+	return &menu, nil
+}
+
+func toMenuRevisionEntity(data model.MenuRevision) (*entity.MenuRevisionEntity, error) {
+	revisionEntity := entity.MenuRevisionEntity{
+		ID:        data.ID,
+		GroupID:   data.GroupID,
+		MenuID:    data.MenuID,
+		Author:    data.Author,
+		Operation: data.Operation,
+		CreatedAt: data.CreatedAt,
+	}
+
+	var err error
+	if revisionEntity.Before, err = unmarshalMenu(data.Before); err != nil {
+		return nil, err
+	}
+	if revisionEntity.After, err = unmarshalMenu(data.After); err != nil {
+		return nil, err
+	}
+	if len(data.Changes) > 0 {
+		if err := json.Unmarshal(data.Changes, &revisionEntity.Changes); err != nil {
+			return nil, err
+		}
+	}
+	if len(data.Snapshot) > 0 {
+		if err := json.Unmarshal(data.Snapshot, &revisionEntity.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	return &revisionEntity, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MenuTranslationRepositoryInterface interface {
+	FindTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error)
+	FindPublishedTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error)
+	SaveTranslation(ctx context.Context, req entity.MenuTranslationEntity) error
+	DeleteTranslation(ctx context.Context, menuID uuid.UUID, locale string) error
+	ResetTranslations(ctx context.Context, groupID uuid.UUID) error
+}
+
+type MenuTranslationRepository struct {
+	DB *gorm.DB
+}
+
+func NewMenuTranslationRepository(db *gorm.DB) MenuTranslationRepositoryInterface {
+	return &MenuTranslationRepository{
+		DB: db,
+	}
+}
+
+func (m *MenuTranslationRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, m.DB)
+}
+
+// FindTranslations implements MenuTranslationRepositoryInterface.
+// It returns every draft translation of the given menus, ordered by menu and locale.
+func (m *MenuTranslationRepository) FindTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error) {
+	if len(menuIDs) == 0 {
+		return nil, nil
+	}
+
+	modelTranslations := []model.MenuTranslation{}
+
+	if err := m.db(ctx).Where("menu_id IN ?", menuIDs).Order("menu_id, locale").Find(&modelTranslations).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindTranslations - 1")
+		return nil, err
+	}
+
+	return toMenuTranslationEntities(modelTranslations), nil
+}
+
+// FindPublishedTranslations implements MenuTranslationRepositoryInterface.
+// It returns the translations the given menus had at the last publish of their group,
+// ordered by menu and locale.
+func (m *MenuTranslationRepository) FindPublishedTranslations(ctx context.Context, menuIDs []uuid.UUID) ([]entity.MenuTranslationEntity, error) {
+	if len(menuIDs) == 0 {
+		return nil, nil
+	}
+
+	modelTranslations := []model.MenuTranslation{}
+
+	if err := m.db(ctx).Table("published_menu_translations").Where("menu_id IN ?", menuIDs).Order("menu_id, locale").Find(&modelTranslations).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] FindPublishedTranslations - 1")
+		return nil, err
+	}
+
+	return toMenuTranslationEntities(modelTranslations), nil
+}
+
+func toMenuTranslationEntities(modelTranslations []model.MenuTranslation) []entity.MenuTranslationEntity {
+	translations := make([]entity.MenuTranslationEntity, 0, len(modelTranslations))
+	for _, data := range modelTranslations {
+		translations = append(translations, entity.MenuTranslationEntity{
+			MenuID:    data.MenuID,
+			Locale:    data.Locale,
+			Name:      data.Name,
+			UpdatedAt: data.UpdatedAt,
+		})
+	}
+
+	return translations
+}
+
+// SaveTranslation implements MenuTranslationRepositoryInterface.
+// An existing translation of the same locale is overwritten.
+func (m *MenuTranslationRepository) SaveTranslation(ctx context.Context, req entity.MenuTranslationEntity) error {
+	modelTranslation := model.MenuTranslation{
+		MenuID: req.MenuID,
+		Locale: req.Locale,
+		Name:   req.Name,
+	}
+
+	err := m.db(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "menu_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&modelTranslation).Error
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] SaveTranslation - 1")
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTranslation implements MenuTranslationRepositoryInterface.
+func (m *MenuTranslationRepository) DeleteTranslation(ctx context.Context, menuID uuid.UUID, locale string) error {
+	result := m.db(ctx).Where("menu_id = ? AND locale = ?", menuID, locale).Delete(&model.MenuTranslation{})
+	if result.Error != nil {
+		log.Err(result.Error).Msg("[REPOSITORY] DeleteTranslation - 1")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("translation not found")
+	}
+
+	return nil
+}
+
+// ResetTranslations implements MenuTranslationRepositoryInterface.
+// The draft translations of the live menus of the group are replaced by the ones
+// published with them, for when the draft is reset to the published tree.
+func (m *MenuTranslationRepository) ResetTranslations(ctx context.Context, groupID uuid.UUID) error {
+	deleteTranslations := `
+		DELETE FROM menu_translations t
+		USING menus m
+		WHERE m.id = t.menu_id AND m.group_id = $1 AND m.deleted_at IS NULL
+	`
+
+	copyTranslations := `
+		INSERT INTO menu_translations (menu_id, locale, name, created_at, updated_at)
+		SELECT t.menu_id, t.locale, t.name, t.created_at, t.updated_at
+		FROM published_menu_translations t
+		JOIN published_menus p ON p.id = t.menu_id
+		WHERE p.group_id = $1
+	`
+
+	if err := m.db(ctx).Exec(deleteTranslations, groupID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] ResetTranslations - 1")
+		return err
+	}
+
+	if err := m.db(ctx).Exec(copyTranslations, groupID).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] ResetTranslations - 2")
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"golang_menu_interview/core/domain/model"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type RateLimitRepositoryInterface interface {
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
+type RateLimitRepository struct {
+	DB *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewRateLimitRepository(db *gorm.DB) RateLimitRepositoryInterface {
+	return &RateLimitRepository{
+		DB: db,
+	}
+}
+
+func (r *RateLimitRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.DB)
+}
+
+// Hit implements RateLimitRepositoryInterface.
+// The count is bumped, or a new window started, in one statement so replicas hitting
+// the same key at once all count. Ended windows are swept once a minute.
+func (r *RateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	now := time.Now().UTC()
+
+	query := `
+		INSERT INTO rate_limits (key, hits, reset_at) VALUES ($1, 1, $2)
+		ON CONFLICT (key) DO UPDATE SET
+			hits = CASE WHEN rate_limits.reset_at <= $3 THEN 1 ELSE rate_limits.hits + 1 END,
+			reset_at = CASE WHEN rate_limits.reset_at <= $3 THEN EXCLUDED.reset_at ELSE rate_limits.reset_at END
+		RETURNING hits, reset_at
+	`
+
+	row := model.RateLimit{}
+	if err := r.db(ctx).Raw(query, key, now.Add(window), now).Scan(&row).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] Hit - 1")
+		return 0, time.Time{}, err
+	}
+
+	r.mu.Lock()
+	sweep := now.Sub(r.lastSweep) > time.Minute
+	if sweep {
+		r.lastSweep = now
+	}
+	r.mu.Unlock()
+
+	if sweep {
+		if err := r.db(ctx).Where("reset_at <= ?", now).Delete(&model.RateLimit{}).Error; err != nil {
+			log.Err(err).Msg("[REPOSITORY] Hit - 2")
+		}
+	}
+
+	return row.Hits, row.ResetAt, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/model"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RevokedTokenRepositoryInterface interface {
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+type RevokedTokenRepository struct {
+	DB *gorm.DB
+}
+
+func NewRevokedTokenRepository(db *gorm.DB) RevokedTokenRepositoryInterface {
+	return &RevokedTokenRepository{
+		DB: db,
+	}
+}
+
+func (r *RevokedTokenRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, r.DB)
+}
+
+// RevokeToken implements RevokedTokenRepositoryInterface.
+// Revoking a token twice fails, so only one of two concurrent refreshes wins. Entries
+// of tokens that have expired by now are dropped on the way, since an expired token is
+// refused anyway.
+func (r *RevokedTokenRepository) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	modelToken := model.RevokedToken{
+		JTI:       tokenID,
+		ExpiresAt: expiresAt.UTC(),
+	}
+
+	result := r.db(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&modelToken)
+	if result.Error != nil {
+		log.Err(result.Error).Msg("[REPOSITORY] RevokeToken - 1")
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("token already revoked")
+	}
+
+	if err := r.db(ctx).Where("expires_at < ?", time.Now().UTC()).Delete(&model.RevokedToken{}).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] RevokeToken - 2")
+		return err
+	}
+
+	return nil
+}
+
+// IsRevoked implements RevokedTokenRepositoryInterface.
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var count int64
+
+	if err := r.db(ctx).Model(&model.RevokedToken{}).Where("jti = ?", tokenID).Count(&count).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] IsRevoked - 1")
+		return false, err
+	}
+
+	return count > 0, nil
+}
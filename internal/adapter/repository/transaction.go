@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// withTx runs fn inside a transaction on db. The transaction travels in the
+// context handed to fn, so every repository call made with that context joins it.
+func withTx(ctx context.Context, db *gorm.DB, fn func(ctx context.Context) error) error {
+	return conn(ctx, db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
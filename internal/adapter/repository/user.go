@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"golang_menu_interview/core/domain/entity"
+	"golang_menu_interview/core/domain/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type UserRepositoryInterface interface {
+	CreateUser(ctx context.Context, req entity.UserEntity) error
+	FindUserByUsername(ctx context.Context, username string) (*entity.UserEntity, error)
+	RecordFailedLogin(ctx context.Context, id uuid.UUID, maxFailures int, lockUntil time.Time) (*time.Time, error)
+	ResetFailedLogins(ctx context.Context, id uuid.UUID) error
+}
+
+type UserRepository struct {
+	DB *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepositoryInterface {
+	return &UserRepository{
+		DB: db,
+	}
+}
+
+func (u *UserRepository) db(ctx context.Context) *gorm.DB {
+	return conn(ctx, u.DB)
+}
+
+// CreateUser implements UserRepositoryInterface.
+func (u *UserRepository) CreateUser(ctx context.Context, req entity.UserEntity) error {
+	modelUser := model.User{
+		Username:     req.Username,
+		PasswordHash: req.PasswordHash,
+		Roles:        nonNil(req.Roles),
+		Permissions:  nonNil(req.Permissions),
+		Scopes:       nonNil(req.Scopes),
+	}
+
+	if err := u.db(ctx).Create(&modelUser).Error; err != nil {
+		log.Err(err).Msg("[REPOSITORY] CreateUser - 1")
+		return err
+	}
+
+	return nil
+}
+
+// FindUserByUsername implements UserRepositoryInterface.
+func (u *UserRepository) FindUserByUsername(ctx context.Context, username string) (*entity.UserEntity, error) {
+	modelUser := model.User{}
+
+	if err := u.db(ctx).Where("username = ?", username).First(&modelUser).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		log.Err(err).Msg("[REPOSITORY] FindUserByUsername - 1")
+		return nil, err
+	}
+
+	return &entity.UserEntity{
+		ID:           modelUser.ID,
+		Username:     modelUser.Username,
+		PasswordHash: modelUser.PasswordHash,
+		Roles:        modelUser.Roles,
+		Permissions:  modelUser.Permissions,
+		Scopes:       modelUser.Scopes,
+		FailedLogins: modelUser.FailedLogins,
+		LockedUntil:  modelUser.LockedUntil,
+		CreatedAt:    modelUser.CreatedAt,
+	}, nil
+}
+
+// RecordFailedLogin implements UserRepositoryInterface.
+// The counter is bumped in one statement so concurrent attempts all count. Reaching
+// maxFailures locks the account until lockUntil and starts the count over; the
+// returned time is set only when this attempt locked it.
+func (u *UserRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID, maxFailures int, lockUntil time.Time) (*time.Time, error) {
+	query := `
+		UPDATE users SET
+			failed_logins = CASE WHEN failed_logins + 1 >= $2 THEN 0 ELSE failed_logins + 1 END,
+			locked_until = CASE WHEN failed_logins + 1 >= $2 THEN $3 ELSE locked_until END,
+			updated_at = now()
+		WHERE id = $1
+		RETURNING failed_logins = 0 AS locked
+	`
+
+	var locked bool
+	if err := u.db(ctx).Raw(query, id, maxFailures, lockUntil).Row().Scan(&locked); err != nil {
+		log.Err(err).Msg("[REPOSITORY] RecordFailedLogin - 1")
+		return nil, err
+	}
+
+	if !locked {
+		return nil, nil
+	}
+	return &lockUntil, nil
+}
+
+// ResetFailedLogins implements UserRepositoryInterface.
+func (u *UserRepository) ResetFailedLogins(ctx context.Context, id uuid.UUID) error {
+	err := u.db(ctx).Model(&model.User{}).Where("id = ? AND (failed_logins > 0 OR locked_until IS NOT NULL)", id).
+		Updates(map[string]any{"failed_logins": 0, "locked_until": nil}).Error
+	if err != nil {
+		log.Err(err).Msg("[REPOSITORY] ResetFailedLogins - 1")
+		return err
+	}
+
+	return nil
+}
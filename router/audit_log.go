@@ -0,0 +1,21 @@
+package router
+
+import (
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func AuditLogRouter(api fiber.Router, db *gorm.DB) {
+
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	auditLogService := service.NewAuditLogService(auditLogRepository)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogService)
+
+	api.Get("/audit", middleware.RequireRole(claims.RoleAdmin), auditLogHandler.FindAllAuditLog)
+}
@@ -0,0 +1,27 @@
+package router
+
+import (
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/jwtauth"
+	"golang_menu_interview/utils/middleware"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func AuthRouter(api fiber.Router, db *gorm.DB, validator *validator.Validate, cfg *config.Config, verifier *jwtauth.Verifier, limits middleware.RateLimitStore) {
+
+	userRepository := repository.NewUserRepository(db)
+	revokedTokenRepository := repository.NewRevokedTokenRepository(db)
+	authService := service.NewAuthService(userRepository, revokedTokenRepository, jwtauth.NewSigner(cfg.Auth), verifier, cfg.Auth)
+	authHandler := handler.NewAuthHandler(authService, validator)
+
+	auth := api.Group("/auth", middleware.AuthRateLimiter(cfg.RateLimit, limits))
+	auth.Post("/login", authHandler.Login)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/logout", authHandler.Logout)
+}
@@ -1,26 +1,63 @@
 package router
 
 import (
+	"golang_menu_interview/config"
+	"golang_menu_interview/core/domain/entity"
 	"golang_menu_interview/core/service"
 	"golang_menu_interview/internal/adapter/handler"
 	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/menucache"
+	"golang_menu_interview/utils/middleware"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func MenuRouter(api fiber.Router, db *gorm.DB, validator *validator.Validate) {
+func MenuRouter(api fiber.Router, write fiber.Handler, db *gorm.DB, validator *validator.Validate, cfg *config.Config) {
 
 	menuRepository := repository.NewMenuRepository(db)
-	menuService := service.NewMenuService(menuRepository)
-	menuHandler := handler.NewMenuHandler(menuService, validator)
+	menuGroupRepository := repository.NewMenuGroupRepository(db)
+	menuRevisionRepository := repository.NewMenuRevisionRepository(db)
+	menuPublicationRepository := repository.NewMenuPublicationRepository(db)
+	menuTranslationRepository := repository.NewMenuTranslationRepository(db)
+	auditLogRepository := repository.NewAuditLogRepository(db)
+	menuCache := menucache.New(cfg.Menu.CacheMaxEntries)
+	menuService := service.NewMenuService(menuRepository, menuGroupRepository, menuRevisionRepository, menuPublicationRepository, menuTranslationRepository, auditLogRepository, menuCache, cfg.Menu)
+	menuHandler := handler.NewMenuHandler(menuService, validator, menuCache)
 
-	api.Get("/menus", menuHandler.FindAllMenu)
-	api.Get("/menus/:id", menuHandler.FindMenuByID)
-	api.Post("/menus", menuHandler.CreateMenu)
-	api.Put("/menus/:id", menuHandler.UpdateMenu)
-	api.Delete("/menus/:id", menuHandler.DeleteMenu)
-	api.Patch("/menus/:id/move", menuHandler.MoveMenu)
-	api.Patch("/menus/:id/reorder", menuHandler.ReorderMenu)
+	// the published tree is public, the draft and its history only for editors
+	draft := func(c *fiber.Ctx) error {
+		if c.Query("version") == entity.MenuVersionDraft || c.Query("as_of") != "" {
+			return write(c)
+		}
+		return c.Next()
+	}
+
+	api.Get("/menus", draft, menuHandler.FindAllMenu)
+	api.Get("/menus/cache", middleware.RequireRole(claims.RoleAdmin), menuHandler.FindCacheStats)
+	api.Get("/menus/trash", write, menuHandler.FindTrashedMenu)
+	api.Get("/menus/flat", draft, menuHandler.FindMenuList)
+	api.Get("/menus/search", draft, menuHandler.SearchMenu)
+	// revisions carry whole menus, restricted ones included, so only admins list them
+	api.Get("/menus/revisions", middleware.RequireRole(claims.RoleAdmin), menuHandler.FindAllRevision)
+	api.Get("/menus/:id", draft, menuHandler.FindMenuByID)
+	api.Get("/menus/:id/ancestors", draft, menuHandler.FindAncestors)
+	api.Get("/menus/:id/translations", draft, menuHandler.FindTranslations)
+	api.Post("/menus", write, menuHandler.CreateMenu)
+	api.Post("/menus/publish", write, menuHandler.PublishMenu)
+	api.Post("/menus/discard", write, menuHandler.DiscardDraft)
+	api.Put("/menus/tree", write, menuHandler.ReplaceMenuTree)
+	api.Put("/menus/roots/order", write, menuHandler.ReorderRoots)
+	api.Put("/menus/:id", write, menuHandler.UpdateMenu)
+	api.Delete("/menus/:id", write, menuHandler.DeleteMenu)
+	api.Patch("/menus/:id/move", write, menuHandler.MoveMenu)
+	api.Patch("/menus/:id/reorder", write, menuHandler.ReorderMenu)
+	api.Put("/menus/:id/children/order", write, menuHandler.ReorderChildren)
+	api.Post("/menus/:id/restore", write, menuHandler.RestoreMenu)
+	// a rollback rewrites the whole tree, restricted menus included
+	api.Post("/menus/revisions/:id/rollback", middleware.RequireRole(claims.RoleAdmin), menuHandler.RollbackRevision)
+	api.Put("/menus/:id/translations/:locale", write, menuHandler.SaveTranslation)
+	api.Delete("/menus/:id/translations/:locale", write, menuHandler.DeleteTranslation)
 }
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"golang_menu_interview/core/service"
+	"golang_menu_interview/internal/adapter/handler"
+	"golang_menu_interview/internal/adapter/repository"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func MenuGroupRouter(api fiber.Router, write fiber.Handler, db *gorm.DB, validator *validator.Validate) {
+
+	menuGroupRepository := repository.NewMenuGroupRepository(db)
+	menuGroupService := service.NewMenuGroupService(menuGroupRepository)
+	menuGroupHandler := handler.NewMenuGroupHandler(menuGroupService, validator)
+
+	api.Get("/menu-groups", menuGroupHandler.FindAllMenuGroup)
+	api.Get("/menu-groups/:id", menuGroupHandler.FindMenuGroupByID)
+	api.Post("/menu-groups", write, menuGroupHandler.CreateMenuGroup)
+	api.Put("/menu-groups/:id", write, menuGroupHandler.UpdateMenuGroup)
+	api.Delete("/menu-groups/:id", write, menuGroupHandler.DeleteMenuGroup)
+}
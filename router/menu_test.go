@@ -0,0 +1,80 @@
+package router
+
+import (
+	"golang_menu_interview/config"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/middleware"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// TestMenuHistoryAccess checks the routes guarding the draft and its history. Every
+// request is refused before it reaches the database, which is never connected.
+func TestMenuHistoryAccess(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{}
+	cfg.Auth.WriteScope = "menus:write"
+
+	var caller claims.Claims
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.SetUserContext(claims.NewContext(c.UserContext(), caller))
+		return c.Next()
+	})
+	MenuRouter(app, middleware.RequireScope(cfg.Auth.WriteScope), db, validator.New(), cfg)
+
+	group := "group_id=" + uuid.NewString()
+	editor := claims.Claims{Subject: "editor", Scopes: []string{"menus:write"}}
+	tests := []struct {
+		name   string
+		caller claims.Claims
+		method string
+		target string
+		status int
+	}{
+		{name: "draft tree anonymously", target: "/menus?version=draft&" + group, status: fiber.StatusUnauthorized},
+		{name: "tree as of a revision anonymously", target: "/menus?as_of=1&" + group, status: fiber.StatusUnauthorized},
+		{name: "draft subtree anonymously", target: "/menus/" + uuid.NewString() + "?version=draft", status: fiber.StatusUnauthorized},
+		{name: "draft flat listing anonymously", target: "/menus/flat?version=draft&" + group, status: fiber.StatusUnauthorized},
+		{name: "draft search anonymously", target: "/menus/search?q=home&version=draft&" + group, status: fiber.StatusUnauthorized},
+		{name: "draft breadcrumb anonymously", target: "/menus/" + uuid.NewString() + "/ancestors?version=draft", status: fiber.StatusUnauthorized},
+		{name: "draft translations anonymously", target: "/menus/" + uuid.NewString() + "/translations?version=draft", status: fiber.StatusUnauthorized},
+		{name: "trash anonymously", target: "/menus/trash?" + group, status: fiber.StatusUnauthorized},
+		{name: "revisions anonymously", target: "/menus/revisions?" + group, status: fiber.StatusUnauthorized},
+		{name: "revisions as an editor", caller: editor, target: "/menus/revisions?" + group, status: fiber.StatusForbidden},
+		{name: "rollback as an editor", caller: editor, method: fiber.MethodPost, target: "/menus/revisions/1/rollback", status: fiber.StatusForbidden},
+		{name: "cache stats anonymously", target: "/menus/cache", status: fiber.StatusUnauthorized},
+		{name: "cache stats as an editor", caller: editor, target: "/menus/cache", status: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller = tt.caller
+
+			method := tt.method
+			if method == "" {
+				method = fiber.MethodGet
+			}
+
+			resp, err := app.Test(httptest.NewRequest(method, tt.target, nil))
+			if err != nil {
+				t.Fatalf("%s %s: %v", method, tt.target, err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
@@ -2,6 +2,9 @@ package router
 
 import (
 	"golang_menu_interview/config"
+	"golang_menu_interview/internal/adapter/repository"
+	"golang_menu_interview/utils/jwtauth"
+	"golang_menu_interview/utils/middleware"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -48,7 +51,30 @@ func Init(config *config.Config) *fiber.App {
 		log.Error().Err(err).Msg("Error connecting to database")
 	}
 
-	api := app.Group("/api")
+	verifier, err := jwtauth.NewVerifier(config.Auth, repository.NewRevokedTokenRepository(db.DB))
+	if err != nil {
+		log.Fatal().Err(err).Msg("Error loading JWT keys")
+	}
+	if !verifier.Configured() {
+		log.Warn().Msg("no JWT secret or JWKS file configured, write endpoints refuse every request")
+	}
+
+	var limits middleware.RateLimitStore = middleware.NewMemoryRateLimitStore()
+	if config.RateLimit.Store == "postgres" {
+		limits = repository.NewRateLimitRepository(db.DB)
+	}
+
+	// the IP limiter runs before Authenticate so bad tokens are counted too, the others
+	// after it so they can key on the token subject
+	api := app.Group("/api",
+		middleware.RequestInfo(config.RateLimit),
+		middleware.IPRateLimiter(config.RateLimit, limits),
+		middleware.Authenticate(verifier),
+		middleware.APIRateLimiter(config.RateLimit, limits),
+		middleware.WriteRateLimiter(config.RateLimit, limits),
+		middleware.Locale(),
+	)
+	write := middleware.RequireScope(config.Auth.WriteScope)
 
 	// check api run
 	api.Get("/check", func(c *fiber.Ctx) error {
@@ -59,7 +85,10 @@ func Init(config *config.Config) *fiber.App {
 		})
 	})
 
-	MenuRouter(api, db.DB, validator)
+	AuthRouter(api, db.DB, validator, config, verifier, limits)
+	MenuGroupRouter(api, write, db.DB, validator)
+	MenuRouter(api, write, db.DB, validator, config)
+	AuditLogRouter(api, db.DB)
 
 	return app
 
@@ -0,0 +1,21 @@
+package actor
+
+import "context"
+
+// System is the author of changes made without a known actor, such as CLI commands.
+const System = "system"
+
+type actorKey struct{}
+
+// NewContext returns a copy of ctx that names who is making the changes.
+func NewContext(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, actorKey{}, name)
+}
+
+// FromContext returns the actor carried by ctx, or System when there is none.
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(actorKey{}).(string); ok && name != "" {
+		return name
+	}
+	return System
+}
@@ -0,0 +1,44 @@
+package claims
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// RoleAdmin sees every menu and may preview the tree as another role.
+const RoleAdmin = "admin"
+
+// Claims identify the caller and what they are allowed to see and do. TokenID and
+// ExpiresAt describe the token they came from, so it can be revoked.
+type Claims struct {
+	Subject     string
+	Roles       []string
+	Permissions []string
+	Scopes      []string
+	TokenID     string
+	ExpiresAt   time.Time
+}
+
+// HasRole reports whether the claims grant role.
+func (c Claims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasScope reports whether the claims grant scope.
+func (c Claims) HasScope(scope string) bool {
+	return slices.Contains(c.Scopes, scope)
+}
+
+type claimsKey struct{}
+
+// NewContext returns a copy of ctx carrying the claims of the caller.
+func NewContext(ctx context.Context, c Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, c)
+}
+
+// FromContext returns the claims carried by ctx, empty for an anonymous caller.
+func FromContext(ctx context.Context) Claims {
+	c, _ := ctx.Value(claimsKey{}).(Claims)
+	return c
+}
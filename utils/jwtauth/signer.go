@@ -0,0 +1,65 @@
+package jwtauth
+
+import (
+	"errors"
+	"golang_menu_interview/config"
+	"golang_menu_interview/utils/claims"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Signer issues HS256 tokens with the configured secret, issuer and audience.
+type Signer struct {
+	secret   []byte
+	issuer   string
+	audience string
+}
+
+func NewSigner(cfg config.Auth) *Signer {
+	return &Signer{
+		secret:   []byte(cfg.JWTSecret),
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+	}
+}
+
+// Sign issues a token of tokenType for caller, valid for ttl. The returned claims hold
+// the id and expiry of the new token. Refresh tokens only carry the subject.
+func (s *Signer) Sign(caller claims.Claims, tokenType string, ttl time.Duration) (string, claims.Claims, error) {
+	if len(s.secret) == 0 {
+		return "", claims.Claims{}, errors.New("token signing is not configured")
+	}
+
+	now := time.Now()
+	caller.TokenID = uuid.NewString()
+	caller.ExpiresAt = now.Add(ttl)
+
+	payload := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        caller.TokenID,
+			Subject:   caller.Subject,
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(caller.ExpiresAt),
+		},
+		Type: tokenType,
+	}
+	if s.audience != "" {
+		payload.Audience = jwt.ClaimStrings{s.audience}
+	}
+	if tokenType == TokenAccess {
+		payload.Scope = strings.Join(caller.Scopes, " ")
+		payload.Roles = caller.Roles
+		payload.Permissions = caller.Permissions
+	}
+
+	tokenString, err := jwt.NewWithClaims(jwt.SigningMethodHS256, payload).SignedString(s.secret)
+	if err != nil {
+		return "", claims.Claims{}, err
+	}
+
+	return tokenString, caller, nil
+}
@@ -0,0 +1,157 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"golang_menu_interview/config"
+	"golang_menu_interview/utils/claims"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Token types. Tokens from other issuers carry no type and count as access tokens.
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
+// leeway absorbs clock drift between the token issuer and this server.
+const leeway = 30 * time.Second
+
+// tokenClaims is the payload of a token. Scope is space separated, as in OAuth 2.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Type        string   `json:"typ,omitempty"`
+	Scope       string   `json:"scope,omitempty"`
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// RevocationList tells whether a token was revoked before it expired.
+type RevocationList interface {
+	IsRevoked(ctx context.Context, tokenID string) (bool, error)
+}
+
+// Verifier checks the signature, registered claims and revocation of tokens.
+type Verifier struct {
+	secret      []byte
+	keys        map[string]crypto.PublicKey
+	parser      *jwt.Parser
+	revocations RevocationList
+}
+
+// NewVerifier builds a Verifier accepting HS256 tokens when a secret is configured
+// and RS256 or ES256 tokens signed by a key of the JWKS file when one is configured.
+// Token ids are looked up in revocations unless it is nil.
+func NewVerifier(cfg config.Auth, revocations RevocationList) (*Verifier, error) {
+	var (
+		verifier = &Verifier{revocations: revocations}
+		methods  []string
+	)
+
+	if cfg.JWTSecret != "" {
+		verifier.secret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+	verifier.parser = jwt.NewParser(options...)
+
+	return verifier, nil
+}
+
+// Configured reports whether any key to verify tokens with is set up.
+func (v *Verifier) Configured() bool {
+	return len(v.secret) > 0 || len(v.keys) > 0
+}
+
+// Verify checks an access token and returns the claims of its caller.
+func (v *Verifier) Verify(ctx context.Context, tokenString string) (claims.Claims, error) {
+	return v.verify(ctx, tokenString, TokenAccess)
+}
+
+// VerifyRefresh checks a refresh token and returns the claims it carries.
+func (v *Verifier) VerifyRefresh(ctx context.Context, tokenString string) (claims.Claims, error) {
+	return v.verify(ctx, tokenString, TokenRefresh)
+}
+
+func (v *Verifier) verify(ctx context.Context, tokenString, tokenType string) (claims.Claims, error) {
+	if !v.Configured() {
+		return claims.Claims{}, errors.New("token authentication is not configured")
+	}
+
+	payload := tokenClaims{}
+	if _, err := v.parser.ParseWithClaims(tokenString, &payload, v.key); err != nil {
+		return claims.Claims{}, err
+	}
+
+	if payload.Type != tokenType && !(payload.Type == "" && tokenType == TokenAccess) {
+		return claims.Claims{}, errors.New("token type must be " + tokenType)
+	}
+
+	if payload.Subject == "" {
+		return claims.Claims{}, errors.New("token has no subject")
+	}
+
+	if v.revocations != nil && payload.ID != "" {
+		revoked, err := v.revocations.IsRevoked(ctx, payload.ID)
+		if err != nil {
+			return claims.Claims{}, err
+		}
+		if revoked {
+			return claims.Claims{}, errors.New("token has been revoked")
+		}
+	}
+
+	return claims.Claims{
+		Subject:     payload.Subject,
+		Roles:       payload.Roles,
+		Permissions: payload.Permissions,
+		Scopes:      strings.Fields(payload.Scope),
+		TokenID:     payload.ID,
+		ExpiresAt:   payload.ExpiresAt.Time,
+	}, nil
+}
+
+// key picks the key a token is checked against: the secret for HMAC tokens, otherwise
+// the JWKS key named by the kid header, or the only key when the token names none.
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		return v.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+
+	if kid == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("unknown signing key")
+}
@@ -0,0 +1,134 @@
+package locale
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxTagLength matches the locale column of menu_translations.
+const maxTagLength = 35
+
+type localeKey struct{}
+
+// NewContext returns a copy of ctx carrying the locales the caller asked for, most
+// preferred first.
+func NewContext(ctx context.Context, tags []string) context.Context {
+	return context.WithValue(ctx, localeKey{}, tags)
+}
+
+// FromContext returns the locales carried by ctx, or nil when the caller asked for none.
+func FromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(localeKey{}).([]string)
+	return tags
+}
+
+// Normalize returns tag in its canonical form, such as "en-US" for "en_us", or an
+// empty string when tag is not a well formed language tag.
+func Normalize(tag string) string {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" || len(tag) > maxTagLength {
+		return ""
+	}
+
+	subtags := strings.Split(tag, "-")
+	for i, subtag := range subtags {
+		if subtag == "" || len(subtag) > 8 || strings.IndexFunc(subtag, notAlphaNum) >= 0 {
+			return ""
+		}
+
+		switch {
+		case i == 0:
+			subtag = strings.ToLower(subtag)
+		case len(subtag) == 2:
+			// region, such as US
+			subtag = strings.ToUpper(subtag)
+		case len(subtag) == 4:
+			// script, such as Latn
+			subtag = strings.ToUpper(subtag[:1]) + strings.ToLower(subtag[1:])
+		default:
+			subtag = strings.ToLower(subtag)
+		}
+		subtags[i] = subtag
+	}
+
+	if len(subtags[0]) < 2 || len(subtags[0]) > 3 {
+		return ""
+	}
+
+	return strings.Join(subtags, "-")
+}
+
+func notAlphaNum(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered by
+// their q value. Wildcards, malformed tags and q=0 entries are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var entries []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		tag = Normalize(tag)
+		if tag == "" || q <= 0 {
+			continue
+		}
+		entries = append(entries, weighted{tag: tag, q: q})
+	}
+
+	// stable, so tags of equal weight keep the order the client sent them in
+	slices.SortStableFunc(entries, func(a, b weighted) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	tags := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		tags = append(tags, entry.tag)
+	}
+	return tags
+}
+
+// Chain returns the locales to try in order: the requested ones, then the fallback
+// ones. Each locale is followed by its base language, so "en-US" also matches "en".
+func Chain(requested, fallback []string) []string {
+	var chain []string
+	add := func(tag string) {
+		if tag != "" && !slices.Contains(chain, tag) {
+			chain = append(chain, tag)
+		}
+	}
+
+	for _, tags := range [][]string{requested, fallback} {
+		for _, tag := range tags {
+			tag = Normalize(tag)
+			add(tag)
+			if base, _, found := strings.Cut(tag, "-"); found {
+				add(base)
+			}
+		}
+	}
+
+	return chain
+}
@@ -0,0 +1,139 @@
+package menucache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Entry is a serialized menu tree with the validators sent along with it.
+// LastModified is when the tree was built: visibility windows can change a tree
+// without any write, so the last write is no safe bound.
+type Entry struct {
+	Body         []byte
+	ETag         string
+	LastModified time.Time
+	expiresAt    *time.Time
+}
+
+// Stats counts how the cache has been used since the process started.
+type Stats struct {
+	Hits    uint64 `json:"hits"`
+	Misses  uint64 `json:"misses"`
+	Entries int    `json:"entries"`
+}
+
+// Cache keeps serialized menu trees in memory until the menus change. Every change
+// starts a new generation; a tree built from data read before the change belongs to
+// the old one and is not stored, so a slow read can never bring back a stale tree.
+type Cache struct {
+	mu         sync.RWMutex
+	maxEntries int
+	entries    map[string]Entry
+	generation uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// New returns an empty cache holding at most maxEntries trees.
+func New(maxEntries int) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		entries:    map[string]Entry{},
+	}
+}
+
+// Get returns the tree stored under key, unless it has expired.
+func (c *Cache) Get(key string) (Entry, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+
+	if ok && entry.expiresAt != nil && !time.Now().Before(*entry.expiresAt) {
+		c.mu.Lock()
+		if current, found := c.entries[key]; found && current.expiresAt == entry.expiresAt {
+			delete(c.entries, key)
+		}
+		c.mu.Unlock()
+		ok = false
+	}
+
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return entry, ok
+}
+
+// Generation returns the current generation, to be taken before reading the menus a
+// tree is built from.
+func (c *Cache) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// Put returns body as an entry and stores it under key when generation is still the
+// current one. A tree that changes at expiresAt, such as when a visibility window
+// opens, is dropped from then on.
+func (c *Cache) Put(key string, generation uint64, body []byte, expiresAt *time.Time) Entry {
+	sum := sha256.Sum256(body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := Entry{
+		Body:         body,
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: time.Now().UTC().Truncate(time.Second),
+		expiresAt:    expiresAt,
+	}
+
+	if generation != c.generation {
+		return entry
+	}
+
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		// make room by dropping an arbitrary tree; map order is random enough
+		for other := range c.entries {
+			delete(c.entries, other)
+			break
+		}
+	}
+	if c.maxEntries > 0 {
+		c.entries[key] = entry
+	}
+
+	return entry
+}
+
+// Invalidate drops every tree and starts a new generation. It is called after a change
+// to the menus has been committed. A nil cache ignores it.
+func (c *Cache) Invalidate() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]Entry{}
+	c.generation++
+}
+
+// Stats returns the hit and miss counters and the number of stored trees.
+func (c *Cache) Stats() Stats {
+	c.mu.RLock()
+	entries := len(c.entries)
+	c.mu.RUnlock()
+
+	return Stats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Entries: entries,
+	}
+}
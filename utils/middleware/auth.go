@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/actor"
+	"golang_menu_interview/utils/claims"
+	"golang_menu_interview/utils/jwtauth"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/rs/zerolog/log"
+)
+
+// maxActorLength matches the author column of menu_revisions.
+const maxActorLength = 100
+
+// Authenticate verifies the bearer token of a request when it carries one and puts
+// its claims on the request context, with the subject as the actor recorded for
+// changes. Requests without a token go through anonymously, a bad token is refused.
+func Authenticate(verifier *jwtauth.Verifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		if header == "" {
+			return c.Next()
+		}
+
+		tokenString, found := strings.CutPrefix(header, "Bearer ")
+		if !found {
+			return unauthorized(c, "Authorization header must be a bearer token")
+		}
+
+		caller, err := verifier.Verify(c.UserContext(), strings.TrimSpace(tokenString))
+		if err != nil {
+			log.Error().Err(err).Msg("[MIDDLEWARE] Authenticate - 1")
+			return unauthorized(c, "Invalid or expired token")
+		}
+
+		name := caller.Subject
+		if len(name) > maxActorLength {
+			name = name[:maxActorLength]
+		}
+
+		ctx := claims.NewContext(c.UserContext(), caller)
+		c.SetUserContext(actor.NewContext(ctx, name))
+
+		return c.Next()
+	}
+}
+
+// RequireScope refuses requests that are not authenticated by a token granting scope.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		caller := claims.FromContext(c.UserContext())
+		if caller.Subject == "" {
+			return unauthorized(c, "Authentication required")
+		}
+
+		if !caller.HasScope(scope) {
+			respErr := response.ErrorResponseDefault{}
+			respErr.Message = "Token does not grant the " + scope + " scope"
+			respErr.Status = false
+			return c.Status(fiber.StatusForbidden).JSON(respErr)
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireRole refuses requests that are not authenticated by a token holding role.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		caller := claims.FromContext(c.UserContext())
+		if caller.Subject == "" {
+			return unauthorized(c, "Authentication required")
+		}
+
+		if !caller.HasRole(role) {
+			respErr := response.ErrorResponseDefault{}
+			respErr.Message = "Token does not hold the " + role + " role"
+			respErr.Status = false
+			return c.Status(fiber.StatusForbidden).JSON(respErr)
+		}
+
+		return c.Next()
+	}
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	respErr := response.ErrorResponseDefault{}
+	respErr.Message = message
+	respErr.Status = false
+
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return c.Status(fiber.StatusUnauthorized).JSON(respErr)
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"golang_menu_interview/utils/locale"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locale puts the locales the caller prefers on the request context. The locale query
+// parameter wins over the Accept-Language header, which responses therefore vary on.
+func Locale() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Vary(fiber.HeaderAcceptLanguage)
+
+		var tags []string
+		if tag := locale.Normalize(c.Query("locale")); tag != "" {
+			tags = []string{tag}
+		} else {
+			tags = locale.ParseAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage))
+		}
+
+		if len(tags) > 0 {
+			c.SetUserContext(locale.NewContext(c.UserContext(), tags))
+		}
+
+		return c.Next()
+	}
+}
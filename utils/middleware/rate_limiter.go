@@ -1,55 +1,272 @@
 package middleware
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"golang_menu_interview/config"
+	"golang_menu_interview/internal/adapter/handler/response"
+	"golang_menu_interview/utils/claims"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/rs/zerolog/log"
 )
 
+// RateLimitStore counts requests per key in fixed windows.
+type RateLimitStore interface {
+	// Hit counts one request for key and returns the count of the current window along
+	// with when that window ends. A window starts on the first hit after the last one
+	// ended.
+	Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error)
+}
+
 type RateLimiterConfig struct {
-	Max        int
-	Expiration time.Duration
-	Message    string
+	// Name keeps the counts of each route group apart.
+	Name    string
+	Rule    config.RateLimitRule
+	Message string
+	Store   RateLimitStore
+	Client  config.RateLimit
+	// ByIP keys every client on its IP, ignoring API keys and tokens.
+	ByIP bool
+	// Next skips the limit for requests it returns true for.
+	Next func(c *fiber.Ctx) bool
 }
 
+// NewCustomRateLimiter limits each client to cfg.Rule. Every response carries the
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers, a refused one
+// Retry-After as well. When the store fails the request is let through.
 func NewCustomRateLimiter(cfg RateLimiterConfig) fiber.Handler {
-	if cfg.Max == 0 {
-		cfg.Max = 10
-	}
-	if cfg.Expiration == 0 {
-		cfg.Expiration = 1 * time.Minute
+	if cfg.Rule.Max == 0 {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
 	}
 	if cfg.Message == "" {
 		cfg.Message = "Too Many Requests"
 	}
 
-	return limiter.New(limiter.Config{
-		Max:        cfg.Max,
-		Expiration: cfg.Expiration,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
-				"error": cfg.Message,
-			})
+	clients := newClientKeys(cfg.Client)
+	policy := strconv.Itoa(cfg.Rule.Max) + ";w=" + strconv.Itoa(int(cfg.Rule.Window/time.Second))
+
+	return func(c *fiber.Ctx) error {
+		if cfg.Next != nil && cfg.Next(c) {
+			return c.Next()
+		}
+
+		key := clients.key(c)
+		if cfg.ByIP {
+			key = "ip:" + clients.clientIP(c)
+		}
+
+		count, resetAt, err := cfg.Store.Hit(c.UserContext(), cfg.Name+"|"+key, cfg.Rule.Window)
+		if err != nil {
+			log.Error().Err(err).Msg("[MIDDLEWARE] RateLimiter - 1")
+			return c.Next()
+		}
+
+		reset := int(time.Until(resetAt).Round(time.Second) / time.Second)
+		if reset < 0 {
+			reset = 0
+		}
+
+		c.Set("RateLimit-Policy", policy)
+		c.Set("RateLimit-Limit", strconv.Itoa(cfg.Rule.Max))
+		c.Set("RateLimit-Remaining", strconv.Itoa(max(cfg.Rule.Max-count, 0)))
+		c.Set("RateLimit-Reset", strconv.Itoa(reset))
+
+		if count > cfg.Rule.Max {
+			respErr := response.ErrorResponseDefault{}
+			respErr.Message = cfg.Message
+			respErr.Status = false
+
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(reset))
+			return c.Status(fiber.StatusTooManyRequests).JSON(respErr)
+		}
+
+		return c.Next()
+	}
+}
+
+// IPRateLimiter runs before Authenticate, so floods of bad or revoked tokens are cut
+// off before any of them is verified. Clients sending a known API key are left to
+// APIRateLimiter.
+func IPRateLimiter(cfg config.RateLimit, store RateLimitStore) fiber.Handler {
+	return NewCustomRateLimiter(RateLimiterConfig{
+		Name:    "ip",
+		Rule:    cfg.IP,
+		Message: "Too many requests from this address.",
+		Store:   store,
+		Client:  cfg,
+		ByIP:    true,
+		Next: func(c *fiber.Ctx) bool {
+			apiKey := c.Get(cfg.APIKeyHeader)
+			return apiKey != "" && slices.Contains(cfg.APIKeys, apiKey)
 		},
 	})
 }
 
-func AuthRateLimiter() fiber.Handler {
+func AuthRateLimiter(cfg config.RateLimit, store RateLimitStore) fiber.Handler {
 	return NewCustomRateLimiter(RateLimiterConfig{
-		Max:        3,
-		Expiration: 1 * time.Minute,
-		Message:    "Too many requests. Please try again later.",
+		Name:    "auth",
+		Rule:    cfg.Auth,
+		Message: "Too many requests. Please try again later.",
+		Store:   store,
+		Client:  cfg,
 	})
 }
 
-func APIRateLimiter() fiber.Handler {
+func APIRateLimiter(cfg config.RateLimit, store RateLimitStore) fiber.Handler {
 	return NewCustomRateLimiter(RateLimiterConfig{
-		Max:        100,
-		Expiration: 1 * time.Minute,
-		Message:    "API rate limit exceeded.",
+		Name:    "api",
+		Rule:    cfg.API,
+		Message: "API rate limit exceeded.",
+		Store:   store,
+		Client:  cfg,
 	})
 }
+
+// WriteRateLimiter only counts requests that change something. The auth endpoints
+// have their own limit and are left out.
+func WriteRateLimiter(cfg config.RateLimit, store RateLimitStore) fiber.Handler {
+	return NewCustomRateLimiter(RateLimiterConfig{
+		Name:    "write",
+		Rule:    cfg.Write,
+		Message: "Write rate limit exceeded.",
+		Store:   store,
+		Client:  cfg,
+		Next: func(c *fiber.Ctx) bool {
+			switch c.Method() {
+			case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+				return true
+			}
+			return strings.HasPrefix(c.Path(), "/api/auth/")
+		},
+	})
+}
+
+// clientKeys tells clients apart for rate limiting.
+type clientKeys struct {
+	apiKeyHeader   string
+	apiKeys        []string
+	trustedProxies []*net.IPNet
+}
+
+func newClientKeys(cfg config.RateLimit) clientKeys {
+	keys := clientKeys{apiKeyHeader: cfg.APIKeyHeader, apiKeys: cfg.APIKeys}
+
+	for _, proxy := range cfg.TrustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if strings.Contains(proxy, ":") {
+				proxy += "/128"
+			} else {
+				proxy += "/32"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			log.Error().Err(err).Msg("[MIDDLEWARE] invalid trusted proxy, it is ignored")
+			continue
+		}
+		keys.trustedProxies = append(keys.trustedProxies, network)
+	}
+
+	return keys
+}
+
+// key is a known API key, hashed so it is never stored, else the verified token
+// subject, else the client IP.
+func (k clientKeys) key(c *fiber.Ctx) string {
+	if apiKey := c.Get(k.apiKeyHeader); apiKey != "" && slices.Contains(k.apiKeys, apiKey) {
+		sum := sha256.Sum256([]byte(apiKey))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+
+	if subject := claims.FromContext(c.UserContext()).Subject; subject != "" {
+		return "sub:" + subject
+	}
+
+	return "ip:" + k.clientIP(c)
+}
+
+// clientIP is the peer address, unless the peer is a trusted proxy: X-Forwarded-For is
+// then walked from the right, skipping the trusted proxies, up to the first other hop.
+func (k clientKeys) clientIP(c *fiber.Ctx) string {
+	ip := c.Context().RemoteIP()
+	if !k.trusted(ip) {
+		return ip.String()
+	}
+
+	hops := strings.Split(c.Get(fiber.HeaderXForwardedFor), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		ip = hop
+		if !k.trusted(ip) {
+			break
+		}
+	}
+
+	return ip.String()
+}
+
+func (k clientKeys) trusted(ip net.IP) bool {
+	for _, network := range k.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// MemoryRateLimitStore keeps the counts in this process only.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	windows   map[string]memoryWindow
+	lastSweep time.Time
+}
+
+type memoryWindow struct {
+	count   int
+	resetAt time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		windows: map[string]memoryWindow{},
+	}
+}
+
+// Hit implements RateLimitStore. Ended windows are swept once a minute.
+func (s *MemoryRateLimitStore) Hit(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, w := range s.windows {
+			if !now.Before(w.resetAt) {
+				delete(s.windows, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = memoryWindow{resetAt: now.Add(window)}
+	}
+	w.count++
+	s.windows[key] = w
+
+	return w.count, w.resetAt, nil
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"golang_menu_interview/config"
+	"golang_menu_interview/utils/requestinfo"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxRequestIDLength matches the request_id column of audit_logs.
+const maxRequestIDLength = 128
+
+// RequestInfo puts the client IP and the request ID on the request context. The ID
+// is taken from the X-Request-ID header when the caller sends a usable one, and
+// generated otherwise; either way it is echoed in the response.
+func RequestInfo(cfg config.RateLimit) fiber.Handler {
+	keys := newClientKeys(cfg)
+
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(fiber.HeaderXRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		c.Set(fiber.HeaderXRequestID, requestID)
+
+		c.SetUserContext(requestinfo.NewContext(c.UserContext(), requestinfo.Info{
+			ClientIP:  keys.clientIP(c),
+			RequestID: requestID,
+		}))
+
+		return c.Next()
+	}
+}
+
+// validRequestID accepts printable ASCII without spaces, so the ID is safe to log and
+// export.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
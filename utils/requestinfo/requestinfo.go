@@ -0,0 +1,23 @@
+package requestinfo
+
+import "context"
+
+// Info tells where a request came from, for the audit log.
+type Info struct {
+	ClientIP  string
+	RequestID string
+}
+
+type infoKey struct{}
+
+// NewContext returns a copy of ctx that carries info.
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, infoKey{}, info)
+}
+
+// FromContext returns the info carried by ctx, empty for changes made outside a
+// request, such as CLI commands.
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(infoKey{}).(Info)
+	return info
+}
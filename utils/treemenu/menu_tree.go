@@ -1,24 +1,180 @@
 package treemenu
 
 import (
+	"cmp"
 	"golang_menu_interview/core/domain/entity"
+	"slices"
 
 	"github.com/google/uuid"
 )
 
+// Result is a built tree plus the menus that could not be placed in it.
+type Result struct {
+	Tree []entity.MenuEntity
+	// Orphans are menus whose parent is not part of the input.
+	Orphans []uuid.UUID
+	// Cycles are menus whose parent chain loops back on itself.
+	Cycles []uuid.UUID
+}
+
+// BuildTree returns the children of MenuID (the roots when nil) with their subtrees.
 func BuildTree(allMenus []entity.MenuEntity, MenuID *uuid.UUID) []entity.MenuEntity {
-	var result []entity.MenuEntity
+	return Build(allMenus, MenuID).Tree
+}
 
-	for _, menu := range allMenus {
-		// Check if this menu belongs to current parent
-		if (MenuID == nil && menu.MenuID == nil) ||
-			(MenuID != nil && menu.MenuID != nil && *menu.MenuID == *MenuID) {
+// Build indexes allMenus by parent once and assembles the tree below rootID (the
+// roots when nil) in linear time. Siblings are ordered by sort_order, then name,
+// then id. Orphans and cycles are reported instead of being followed, and are only
+// looked for when the whole forest is built.
+func Build(allMenus []entity.MenuEntity, rootID *uuid.UUID) Result {
+	var (
+		index    = make(map[uuid.UUID]int, len(allMenus))
+		children = make(map[uuid.UUID][]int, len(allMenus))
+		roots    []int
+	)
 
-			// Recursively build children
-			menu.Children = BuildTree(allMenus, &menu.ID)
-			result = append(result, menu)
+	for i, menu := range allMenus {
+		index[menu.ID] = i
+		if menu.MenuID == nil {
+			roots = append(roots, i)
+		} else {
+			children[*menu.MenuID] = append(children[*menu.MenuID], i)
 		}
 	}
 
+	bySortOrder := func(a, b int) int {
+		return cmp.Or(
+			cmp.Compare(allMenus[a].SortOrder, allMenus[b].SortOrder),
+			cmp.Compare(allMenus[a].Name, allMenus[b].Name),
+			slices.Compare(allMenus[a].ID[:], allMenus[b].ID[:]),
+		)
+	}
+	slices.SortFunc(roots, bySortOrder)
+	for _, list := range children {
+		slices.SortFunc(list, bySortOrder)
+	}
+
+	start := roots
+	if rootID != nil {
+		start = children[*rootID]
+	}
+
+	var result Result
+	state := make([]visitState, len(allMenus))
+	result.Tree = assemble(allMenus, children, start, state, &result)
+
+	if rootID == nil {
+		findUnreachable(allMenus, index, state, &result)
+	}
+
 	return result
 }
+
+type visitState uint8
+
+const (
+	unvisited visitState = iota
+	visiting
+	visited
+	unreachable
+)
+
+type frame struct {
+	node  int
+	next  int
+	built []entity.MenuEntity
+}
+
+// assemble builds the subtrees of start with an explicit stack, so neither deep
+// trees nor loops can exhaust the goroutine stack.
+func assemble(allMenus []entity.MenuEntity, children map[uuid.UUID][]int, start []int, state []visitState, result *Result) []entity.MenuEntity {
+	var (
+		tree  []entity.MenuEntity
+		stack []frame
+	)
+
+	push := func(node int) {
+		state[node] = visiting
+		stack = append(stack, frame{node: node})
+	}
+
+	for _, root := range start {
+		if state[root] != unvisited {
+			continue
+		}
+		push(root)
+
+		for len(stack) > 0 {
+			top := &stack[len(stack)-1]
+			kids := children[allMenus[top.node].ID]
+
+			if top.next < len(kids) {
+				child := kids[top.next]
+				top.next++
+
+				if state[child] == visiting {
+					result.Cycles = append(result.Cycles, allMenus[child].ID)
+					continue
+				}
+				if state[child] == unvisited {
+					push(child)
+				}
+				continue
+			}
+
+			menu := allMenus[top.node]
+			menu.Children = top.built
+			state[top.node] = visited
+			stack = stack[:len(stack)-1]
+
+			if len(stack) == 0 {
+				tree = append(tree, menu)
+			} else {
+				parent := &stack[len(stack)-1]
+				parent.built = append(parent.built, menu)
+			}
+		}
+	}
+
+	return tree
+}
+
+// findUnreachable classifies every menu the forest walk did not reach: it either
+// hangs below a missing parent or sits on (or below) a parent loop.
+func findUnreachable(allMenus []entity.MenuEntity, index map[uuid.UUID]int, state []visitState, result *Result) {
+	for i := range allMenus {
+		if state[i] != unvisited {
+			continue
+		}
+
+		// follow the parents until something already classified, a missing parent or a loop
+		var chain []int
+		node := i
+		for {
+			if state[node] == visiting {
+				loopStart := slices.Index(chain, node)
+				for _, member := range chain[loopStart:] {
+					result.Cycles = append(result.Cycles, allMenus[member].ID)
+				}
+				break
+			}
+			if state[node] != unvisited {
+				break
+			}
+
+			state[node] = visiting
+			chain = append(chain, node)
+
+			parent, ok := index[*allMenus[node].MenuID]
+			if !ok {
+				result.Orphans = append(result.Orphans, allMenus[node].ID)
+				break
+			}
+			node = parent
+		}
+
+		for _, member := range chain {
+			state[member] = unreachable
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package treemenu
+
+import (
+	"encoding/binary"
+	"golang_menu_interview/core/domain/entity"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// testID returns a fixed id for n, so expectations can name menus by number.
+func testID(n int) uuid.UUID {
+	var id uuid.UUID
+	binary.BigEndian.PutUint64(id[8:], uint64(n))
+	return id
+}
+
+func testMenu(n int, parent int, name string, sortOrder int) entity.MenuEntity {
+	menu := entity.MenuEntity{ID: testID(n), Name: name, SortOrder: sortOrder}
+	if parent != 0 {
+		parentID := testID(parent)
+		menu.MenuID = &parentID
+	}
+	return menu
+}
+
+// shape renders a tree as name(child child) so whole trees compare as one string.
+func shape(tree []entity.MenuEntity) string {
+	parts := make([]string, 0, len(tree))
+	for _, menu := range tree {
+		if len(menu.Children) == 0 {
+			parts = append(parts, menu.Name)
+			continue
+		}
+		parts = append(parts, menu.Name+"("+shape(menu.Children)+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func sortedIDs(ids []uuid.UUID) []uuid.UUID {
+	ids = slices.Clone(ids)
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return slices.Compare(a[:], b[:])
+	})
+	return ids
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name    string
+		menus   []entity.MenuEntity
+		tree    string
+		orphans []uuid.UUID
+		cycles  []uuid.UUID
+	}{
+		{
+			name: "nested tree",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "a", 0),
+				testMenu(2, 1, "b", 0),
+				testMenu(3, 2, "c", 0),
+				testMenu(4, 0, "d", 1),
+			},
+			tree: "a(b(c)) d",
+		},
+		{
+			name: "orphan and its subtree are left out",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "a", 0),
+				testMenu(2, 9, "orphan", 0),
+				testMenu(3, 2, "below orphan", 0),
+			},
+			tree:    "a",
+			orphans: []uuid.UUID{testID(2)},
+		},
+		{
+			name: "self loop",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "a", 0),
+				testMenu(2, 2, "loop", 0),
+			},
+			tree:   "a",
+			cycles: []uuid.UUID{testID(2)},
+		},
+		{
+			name: "loop of two with a menu hanging below it",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "a", 0),
+				testMenu(4, 2, "below loop", 0),
+				testMenu(2, 3, "x", 0),
+				testMenu(3, 2, "y", 0),
+			},
+			tree:   "a",
+			cycles: []uuid.UUID{testID(2), testID(3)},
+		},
+		{
+			name: "orphans and cycles together",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "a", 0),
+				testMenu(2, 1, "b", 0),
+				testMenu(3, 8, "orphan", 0),
+				testMenu(4, 5, "x", 0),
+				testMenu(5, 4, "y", 0),
+			},
+			tree:    "a(b)",
+			orphans: []uuid.UUID{testID(3)},
+			cycles:  []uuid.UUID{testID(4), testID(5)},
+		},
+		{
+			name: "siblings by sort order then name then id",
+			menus: []entity.MenuEntity{
+				testMenu(1, 0, "root", 0),
+				testMenu(5, 1, "same", 1),
+				testMenu(2, 1, "zeta", 0),
+				testMenu(4, 1, "same", 1),
+				testMenu(3, 1, "alpha", 1),
+				testMenu(6, 1, "first", -1),
+			},
+			tree: "root(first zeta alpha same same)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Build(tt.menus, nil)
+
+			if got := shape(result.Tree); got != tt.tree {
+				t.Errorf("tree = %q, want %q", got, tt.tree)
+			}
+			if got := sortedIDs(result.Orphans); !slices.Equal(got, sortedIDs(tt.orphans)) {
+				t.Errorf("orphans = %v, want %v", got, tt.orphans)
+			}
+			if got := sortedIDs(result.Cycles); !slices.Equal(got, sortedIDs(tt.cycles)) {
+				t.Errorf("cycles = %v, want %v", got, tt.cycles)
+			}
+		})
+	}
+}
+
+func TestBuildDeterministicOrder(t *testing.T) {
+	menus := []entity.MenuEntity{
+		testMenu(1, 0, "root", 0),
+		testMenu(2, 1, "b", 0),
+		testMenu(3, 1, "a", 0),
+		testMenu(4, 1, "a", 0),
+		testMenu(5, 1, "c", -1),
+		testMenu(6, 0, "other root", 0),
+		testMenu(7, 3, "d", 2),
+		testMenu(8, 3, "e", 1),
+	}
+
+	want := Build(menus, nil).Tree
+	if got := shape(want); got != "other root root(c a(e d) a b)" {
+		t.Fatalf("tree = %q", got)
+	}
+
+	// the id breaks the tie between the two menus named a
+	if want[1].Children[1].ID != testID(3) {
+		t.Fatalf("second child = %v, want %v", want[1].Children[1].ID, testID(3))
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := slices.Clone(menus)
+		rng.Shuffle(len(shuffled), func(a, b int) {
+			shuffled[a], shuffled[b] = shuffled[b], shuffled[a]
+		})
+
+		got := Build(shuffled, nil).Tree
+		if shape(got) != shape(want) || got[1].Children[1].ID != want[1].Children[1].ID {
+			t.Fatalf("shuffle %d: tree = %q, want %q", i, shape(got), shape(want))
+		}
+	}
+}
+
+func TestBuildSubtree(t *testing.T) {
+	menus := []entity.MenuEntity{
+		testMenu(1, 0, "a", 0),
+		testMenu(2, 1, "b", 0),
+		testMenu(3, 2, "c", 0),
+		testMenu(4, 9, "orphan", 0),
+	}
+
+	rootID := testID(1)
+	result := Build(menus, &rootID)
+
+	if got := shape(result.Tree); got != "b(c)" {
+		t.Errorf("tree = %q, want %q", got, "b(c)")
+	}
+	// orphans are only looked for when the whole forest is built
+	if len(result.Orphans) != 0 {
+		t.Errorf("orphans = %v, want none", result.Orphans)
+	}
+}
+
+// BenchmarkBuild builds a shuffled tree of 100k menus, ten children per menu.
+func BenchmarkBuild(b *testing.B) {
+	const size = 100_000
+
+	menus := make([]entity.MenuEntity, 0, size)
+	for n := 1; n <= size; n++ {
+		menus = append(menus, testMenu(n, n/10, "menu", n%7))
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	rng.Shuffle(len(menus), func(i, j int) {
+		menus[i], menus[j] = menus[j], menus[i]
+	})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		result := Build(menus, nil)
+		if len(result.Orphans) > 0 || len(result.Cycles) > 0 {
+			b.Fatal("broken parent links in the benchmark tree")
+		}
+	}
+}
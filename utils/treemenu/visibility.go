@@ -0,0 +1,116 @@
+package treemenu
+
+import (
+	"golang_menu_interview/core/domain/entity"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Visible returns the menus shown at the given time: those whose own window holds at
+// and whose ancestors are all shown as well. It also returns the first moment after
+// at when any window opens or closes, nil when none ever does.
+func Visible(allMenus []entity.MenuEntity, at time.Time) ([]entity.MenuEntity, *time.Time) {
+	var nextChange *time.Time
+	for _, menu := range allMenus {
+		for _, edge := range []*time.Time{menu.VisibleFrom, menu.VisibleUntil} {
+			if edge != nil && edge.After(at) && (nextChange == nil || edge.Before(*nextChange)) {
+				nextChange = edge
+			}
+		}
+	}
+
+	return prune(allMenus, func(menu entity.MenuEntity) bool {
+		return inWindow(menu, at)
+	}), nextChange
+}
+
+// Permitted returns the menus a caller with the given roles and permissions may see:
+// those whose own requirements are met and whose ancestors are all permitted as well.
+func Permitted(allMenus []entity.MenuEntity, roles, permissions []string) []entity.MenuEntity {
+	return prune(allMenus, func(menu entity.MenuEntity) bool {
+		if len(menu.RequiredRoles) > 0 && !slices.ContainsFunc(menu.RequiredRoles, func(role string) bool {
+			return slices.Contains(roles, role)
+		}) {
+			return false
+		}
+
+		for _, permission := range menu.RequiredPermissions {
+			if !slices.Contains(permissions, permission) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// prune keeps the menus that pass keep and whose ancestors all do, so a dropped menu
+// takes its whole subtree with it.
+func prune(allMenus []entity.MenuEntity, keep func(entity.MenuEntity) bool) []entity.MenuEntity {
+	var (
+		index = make(map[uuid.UUID]int, len(allMenus))
+		shown = make([]visitState, len(allMenus))
+	)
+
+	for i, menu := range allMenus {
+		index[menu.ID] = i
+	}
+
+	// shown reuses visitState: visited means kept, unreachable means dropped. Parents
+	// are followed with a loop rather than recursion, as in assemble.
+	for i := range allMenus {
+		var (
+			chain       []int
+			parentShown = true
+		)
+
+		for node := i; shown[node] == unvisited; {
+			shown[node] = visiting
+			chain = append(chain, node)
+
+			parentID := allMenus[node].MenuID
+			if parentID == nil {
+				break
+			}
+			parent, found := index[*parentID]
+			if !found {
+				break
+			}
+
+			// visiting means the walk came back to this chain: a loop, kept nowhere
+			if shown[parent] != unvisited {
+				parentShown = shown[parent] == visited
+			}
+			node = parent
+		}
+
+		for j := len(chain) - 1; j >= 0; j-- {
+			ok := parentShown && keep(allMenus[chain[j]])
+			shown[chain[j]] = unreachable
+			if ok {
+				shown[chain[j]] = visited
+			}
+			parentShown = ok
+		}
+	}
+
+	result := make([]entity.MenuEntity, 0, len(allMenus))
+	for i, menu := range allMenus {
+		if shown[i] == visited {
+			result = append(result, menu)
+		}
+	}
+
+	return result
+}
+
+func inWindow(menu entity.MenuEntity, at time.Time) bool {
+	if menu.VisibleFrom != nil && at.Before(*menu.VisibleFrom) {
+		return false
+	}
+	if menu.VisibleUntil != nil && !at.Before(*menu.VisibleUntil) {
+		return false
+	}
+	return true
+}